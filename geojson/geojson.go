@@ -0,0 +1,141 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type GeometryType string
+
+const (
+	Point           GeometryType = "Point"
+	MultiPoint      GeometryType = "MultiPoint"
+	LineString      GeometryType = "LineString"
+	MultiLineString GeometryType = "MultiLineString"
+	Polygon         GeometryType = "Polygon"
+	MultiPolygon    GeometryType = "MultiPolygon"
+)
+
+// Geometry of a feature, coordinates are in [lon, lat] order as per RFC 7946. Coordinates holds
+// []float64 for Point, [][]float64 for MultiPoint and LineString, [][][]float64 for MultiLineString
+// and Polygon, and [][][][]float64 for MultiPolygon.
+type Geometry struct {
+	Type        GeometryType `json:"type"`
+	Coordinates interface{}  `json:"coordinates"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+func NewPoint(coordinates []float64) *Geometry {
+	return &Geometry{Type: Point, Coordinates: coordinates}
+}
+
+func NewMultiPoint(coordinates [][]float64) *Geometry {
+	return &Geometry{Type: MultiPoint, Coordinates: coordinates}
+}
+
+func NewLineString(coordinates [][]float64) *Geometry {
+	return &Geometry{Type: LineString, Coordinates: coordinates}
+}
+
+func NewMultiLineString(coordinates [][][]float64) *Geometry {
+	return &Geometry{Type: MultiLineString, Coordinates: coordinates}
+}
+
+// Rings are closed, the first ring is the exterior and the rest are holes
+func NewPolygon(coordinates [][][]float64) *Geometry {
+	return &Geometry{Type: Polygon, Coordinates: coordinates}
+}
+
+func NewMultiPolygon(coordinates [][][][]float64) *Geometry {
+	return &Geometry{Type: MultiPolygon, Coordinates: coordinates}
+}
+
+// Returns a pointer to a new Feature, a nil properties map is replaced by an empty one
+func NewFeature(id string, geometry *Geometry, properties map[string]interface{}) *Feature {
+	f := new(Feature)
+	f.Type = "Feature"
+	f.ID = id
+	f.Geometry = geometry
+	f.Properties = properties
+	if f.Properties == nil {
+		f.Properties = map[string]interface{}{}
+	}
+	return f
+}
+
+func NewFeatureCollection(features ...*Feature) *FeatureCollection {
+	fc := new(FeatureCollection)
+	fc.Type = "FeatureCollection"
+	fc.Features = features
+	if fc.Features == nil {
+		fc.Features = []*Feature{}
+	}
+	return fc
+}
+
+// Append features to the collection
+func (fc *FeatureCollection) Append(features ...*Feature) {
+	fc.Features = append(fc.Features, features...)
+}
+
+// Writes the collection to file on disk, e.g. ./test.geojson
+func (fc *FeatureCollection) WriteFile(filename string) error {
+	data, err := json.Marshal(fc)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode GeoJSON: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Decodes coordinates into the typed slices documented on Geometry
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Type        GeometryType    `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	g.Type = aux.Type
+
+	var err error
+
+	switch aux.Type {
+	case Point:
+		var c []float64
+		err = json.Unmarshal(aux.Coordinates, &c)
+		g.Coordinates = c
+	case MultiPoint, LineString:
+		var c [][]float64
+		err = json.Unmarshal(aux.Coordinates, &c)
+		g.Coordinates = c
+	case MultiLineString, Polygon:
+		var c [][][]float64
+		err = json.Unmarshal(aux.Coordinates, &c)
+		g.Coordinates = c
+	case MultiPolygon:
+		var c [][][][]float64
+		err = json.Unmarshal(aux.Coordinates, &c)
+		g.Coordinates = c
+	default:
+		return fmt.Errorf("unsupported GeoJSON geometry type '%s'", aux.Type)
+	}
+
+	return err
+}
@@ -0,0 +1,322 @@
+package geojson
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/captchanjack/osmdata/helpers"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+// Converts a decoded Overpass response into a FeatureCollection:
+//
+//   - Nodes with tags, or not part of any way or relation, become Points
+//   - Closed ways that describe an area (see IsArea) become Polygons, other ways LineStrings
+//   - Relations tagged type=multipolygon or type=boundary become MultiPolygons, their outer and
+//     inner member ways stitched into rings
+//
+// Way and relation geometry is taken from geom output when present, otherwise from the nodes and
+// ways in the response (e.g. fetched by recursing down). Ways and rings with missing nodes are
+// left out. Untagged ways that are members of a multipolygon only contribute to its geometry.
+// Tags become properties, along with "@id" and "@type".
+func FromResponse(resp *ovp.Response) *FeatureCollection {
+	fc := NewFeatureCollection()
+
+	nodes := map[int64][]float64{}
+	ways := map[int64]*ovp.WayElement{}
+	referenced := map[int64]bool{}
+	multipolygonWays := map[int64]bool{}
+
+	for _, n := range resp.Nodes() {
+		nodes[n.ID] = []float64{n.Lon, n.Lat}
+	}
+
+	for _, w := range resp.Ways() {
+		ways[w.ID] = w
+		for _, id := range w.Nodes {
+			referenced[id] = true
+		}
+	}
+
+	for _, r := range resp.Relations() {
+		for _, m := range r.Members {
+			if m.Type == ovp.Node {
+				referenced[m.Ref] = true
+			}
+			if m.Type == ovp.Way && isMultipolygon(r.Tags) {
+				multipolygonWays[m.Ref] = true
+			}
+		}
+	}
+
+	for _, e := range resp.Elements {
+		switch e := e.(type) {
+		case *ovp.NodeElement:
+			if len(e.Tags) > 0 || !referenced[e.ID] {
+				fc.Append(newOSMFeature(e, NewPoint([]float64{e.Lon, e.Lat})))
+			}
+
+		case *ovp.WayElement:
+			if len(e.Tags) == 0 && multipolygonWays[e.ID] {
+				continue
+			}
+
+			coordinates, ok := wayCoordinates(e, nodes)
+			if !ok || len(coordinates) < 2 {
+				continue
+			}
+
+			if isClosed(coordinates) && len(coordinates) >= 4 && IsArea(e.Tags) {
+				ring := orientRing(coordinates, true)
+				fc.Append(newOSMFeature(e, NewPolygon([][][]float64{ring})))
+			} else {
+				fc.Append(newOSMFeature(e, NewLineString(coordinates)))
+			}
+
+		case *ovp.RelationElement:
+			if !isMultipolygon(e.Tags) {
+				continue
+			}
+
+			polygons := assembleMultipolygon(e, ways, nodes)
+			if len(polygons) > 0 {
+				fc.Append(newOSMFeature(e, NewMultiPolygon(polygons)))
+			}
+		}
+	}
+
+	return fc
+}
+
+func newOSMFeature(e ovp.Element, geometry *Geometry) *Feature {
+	id := fmt.Sprintf("%s/%d", e.GetType(), e.GetID())
+	properties := make(map[string]interface{}, len(e.GetTags())+2)
+
+	for k, v := range e.GetTags() {
+		properties[k] = v
+	}
+
+	properties["@id"] = id
+	properties["@type"] = string(e.GetType())
+
+	return NewFeature(id, geometry, properties)
+}
+
+func isMultipolygon(tags map[string]string) bool {
+	return tags["type"] == "multipolygon" || tags["type"] == "boundary"
+}
+
+// Keys whose presence on a closed way makes it an area, except for the listed values
+var areaKeys = map[string]map[string]bool{
+	"building":      {},
+	"building:part": {},
+	"landuse":       {},
+	"amenity":       {},
+	"leisure":       {"track": true, "slipway": true},
+	"natural":       {"coastline": true, "cliff": true, "ridge": true, "arete": true, "tree_row": true},
+	"shop":          {},
+	"tourism":       {},
+	"place":         {},
+	"office":        {},
+	"craft":         {},
+	"historic":      {},
+	"military":      {},
+	"ruins":         {},
+	"area:highway":  {},
+	"water":         {},
+	"wetland":       {},
+	"man_made":      {"cutline": true, "embankment": true, "pipeline": true},
+	"aeroway":       {"taxiway": true},
+	"power":         {"line": true, "minor_line": true, "cable": true},
+	"waterway":      {"river": true, "stream": true, "canal": true, "drain": true, "ditch": true},
+}
+
+// Returns true if a closed way with these tags describes an area rather than a closed line, e.g.
+// a building or park rather than a roundabout. area=yes and area=no take precedence.
+func IsArea(tags map[string]string) bool {
+	switch tags["area"] {
+	case "yes":
+		return true
+	case "no":
+		return false
+	}
+
+	for k, v := range tags {
+		if exceptions, ok := areaKeys[k]; ok && v != "no" && !exceptions[v] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isClosed(coordinates [][]float64) bool {
+	first, last := coordinates[0], coordinates[len(coordinates)-1]
+	return first[0] == last[0] && first[1] == last[1]
+}
+
+// Returns the coordinates of a way from its geom output, or from its nodes, ok is false if any
+// coordinate is missing
+func wayCoordinates(w *ovp.WayElement, nodes map[int64][]float64) ([][]float64, bool) {
+	if len(w.Geometry) > 0 {
+		coordinates := make([][]float64, len(w.Geometry))
+		for i, p := range w.Geometry {
+			if p.Lat == 0 && p.Lon == 0 {
+				return nil, false
+			}
+			coordinates[i] = []float64{p.Lon, p.Lat}
+		}
+		return coordinates, true
+	}
+
+	coordinates := make([][]float64, len(w.Nodes))
+	for i, id := range w.Nodes {
+		c, ok := nodes[id]
+		if !ok {
+			return nil, false
+		}
+		coordinates[i] = c
+	}
+	return coordinates, len(coordinates) > 0
+}
+
+// Returns the coordinates of a way member of a relation
+func memberCoordinates(m ovp.Member, ways map[int64]*ovp.WayElement, nodes map[int64][]float64) ([][]float64, bool) {
+	if len(m.Geometry) > 0 {
+		return wayCoordinates(&ovp.WayElement{Geometry: m.Geometry}, nodes)
+	}
+
+	w, ok := ways[m.Ref]
+	if !ok {
+		return nil, false
+	}
+
+	return wayCoordinates(w, nodes)
+}
+
+// Assembles the outer and inner rings of a multipolygon relation into polygons, each outer ring
+// followed by the inner rings it contains. Members without a role are treated as outer.
+func assembleMultipolygon(r *ovp.RelationElement, ways map[int64]*ovp.WayElement, nodes map[int64][]float64) [][][][]float64 {
+	var outerWays, innerWays [][][]float64
+
+	for _, m := range r.Members {
+		if m.Type != ovp.Way {
+			continue
+		}
+
+		coordinates, ok := memberCoordinates(m, ways, nodes)
+		if !ok || len(coordinates) < 2 {
+			continue
+		}
+
+		if m.Role == "inner" {
+			innerWays = append(innerWays, coordinates)
+		} else if m.Role == "outer" || m.Role == "" {
+			outerWays = append(outerWays, coordinates)
+		}
+	}
+
+	outers := StitchRings(outerWays)
+	inners := StitchRings(innerWays)
+
+	// Largest outer first so nested outers (islands within lakes) claim the innermost holes last
+	sort.SliceStable(outers, func(i, j int) bool {
+		return absArea(outers[i]) > absArea(outers[j])
+	})
+
+	polygons := make([][][][]float64, len(outers))
+	for i, outer := range outers {
+		polygons[i] = [][][]float64{orientRing(outer, true)}
+	}
+
+	for _, inner := range inners {
+		// The smallest outer containing the inner ring
+		owner := -1
+		for i, outer := range outers {
+			if helpers.PointInRing(inner[0], outer) {
+				owner = i
+			}
+		}
+
+		if owner >= 0 {
+			polygons[owner] = append(polygons[owner], orientRing(inner, false))
+		}
+	}
+
+	return polygons
+}
+
+// Joins line strings that share end points into closed rings, lines that cannot be closed are
+// dropped. The input slices are not modified.
+func StitchRings(lines [][][]float64) [][][]float64 {
+	var rings [][][]float64
+	remaining := make([][][]float64, 0, len(lines))
+
+	for _, line := range lines {
+		if len(line) >= 4 && isClosed(line) {
+			rings = append(rings, line)
+		} else if len(line) >= 2 {
+			remaining = append(remaining, line)
+		}
+	}
+
+	for len(remaining) > 0 {
+		ring := append([][]float64{}, remaining[0]...)
+		remaining = remaining[1:]
+
+		for !isClosed(ring) {
+			end := ring[len(ring)-1]
+			found := false
+
+			for i, line := range remaining {
+				first, last := line[0], line[len(line)-1]
+
+				if samePoint(first, end) {
+					ring = append(ring, line[1:]...)
+				} else if samePoint(last, end) {
+					reversed := append([][]float64{}, line...)
+					helpers.ReverseCoordinates(reversed)
+					ring = append(ring, reversed[1:]...)
+				} else {
+					continue
+				}
+
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+
+			if !found {
+				break
+			}
+		}
+
+		if isClosed(ring) && len(ring) >= 4 {
+			rings = append(rings, ring)
+		}
+	}
+
+	return rings
+}
+
+func samePoint(a []float64, b []float64) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+func absArea(ring [][]float64) float64 {
+	area := helpers.SignedRingArea(ring)
+	if area < 0 {
+		return -area
+	}
+	return area
+}
+
+// Returns a copy of the ring wound counter-clockwise for exterior rings and clockwise for holes,
+// as required by RFC 7946
+func orientRing(ring [][]float64, exterior bool) [][]float64 {
+	oriented := append([][]float64{}, ring...)
+	if (helpers.SignedRingArea(oriented) > 0) != exterior {
+		helpers.ReverseCoordinates(oriented)
+	}
+	return oriented
+}
@@ -0,0 +1,108 @@
+package geojson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/captchanjack/osmdata/helpers"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+const testResponse = `{
+  "elements": [
+    {"type": "node", "id": 1, "lat": 0, "lon": 0},
+    {"type": "node", "id": 2, "lat": 0, "lon": 10},
+    {"type": "node", "id": 3, "lat": 10, "lon": 10},
+    {"type": "node", "id": 4, "lat": 10, "lon": 0},
+    {"type": "node", "id": 5, "lat": 2, "lon": 2},
+    {"type": "node", "id": 6, "lat": 2, "lon": 4},
+    {"type": "node", "id": 7, "lat": 4, "lon": 4},
+    {"type": "node", "id": 8, "lat": 20, "lon": 20, "tags": {"highway": "traffic_signals"}},
+    {"type": "node", "id": 9, "lat": 21, "lon": 21},
+    {"type": "way", "id": 10, "nodes": [1, 2, 3]},
+    {"type": "way", "id": 11, "nodes": [1, 4, 3]},
+    {"type": "way", "id": 12, "nodes": [5, 6, 7, 5]},
+    {"type": "way", "id": 13, "nodes": [5, 6, 7, 5], "tags": {"building": "yes"}},
+    {"type": "way", "id": 14, "nodes": [5, 6, 7, 5], "tags": {"highway": "residential", "junction": "roundabout"}},
+    {"type": "way", "id": 15, "nodes": [8, 9], "tags": {"highway": "primary"}},
+    {"type": "relation", "id": 100, "members": [
+      {"type": "way", "ref": 10, "role": "outer"},
+      {"type": "way", "ref": 11, "role": "outer"},
+      {"type": "way", "ref": 12, "role": "inner"}
+    ], "tags": {"type": "multipolygon", "landuse": "forest"}}
+  ]
+}`
+
+func TestFromResponse(t *testing.T) {
+	resp, err := ovp.DecodeJSON(strings.NewReader(testResponse))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fc := FromResponse(resp)
+	features := map[string]*Feature{}
+
+	for _, f := range fc.Features {
+		features[f.ID] = f
+	}
+
+	var testCases = []struct {
+		id           string
+		geometryType GeometryType
+	}{
+		{"node/8", Point},
+		{"way/13", Polygon},
+		{"way/14", LineString},
+		{"way/15", LineString},
+		{"relation/100", MultiPolygon},
+	}
+
+	if len(features) != len(testCases) {
+		t.Errorf("expected %d features, got %d", len(testCases), len(features))
+	}
+
+	for _, test := range testCases {
+		f, ok := features[test.id]
+
+		if !ok {
+			t.Errorf("%v: missing feature", test.id)
+			continue
+		}
+
+		if f.Geometry.Type != test.geometryType {
+			t.Errorf("%v: expected %s, got %s", test.id, test.geometryType, f.Geometry.Type)
+		}
+	}
+
+	multipolygon := features["relation/100"].Geometry.Coordinates.([][][][]float64)
+
+	if len(multipolygon) != 1 || len(multipolygon[0]) != 2 {
+		t.Fatalf("expected one polygon with one hole, got %v", multipolygon)
+	}
+
+	if len(multipolygon[0][0]) != 5 || helpers.SignedRingArea(multipolygon[0][0]) <= 0 {
+		t.Errorf("expected a closed counter-clockwise outer ring, got %v", multipolygon[0][0])
+	}
+
+	if helpers.SignedRingArea(multipolygon[0][1]) >= 0 {
+		t.Errorf("expected a clockwise inner ring, got %v", multipolygon[0][1])
+	}
+
+	if features["relation/100"].Properties["landuse"] != "forest" || features["relation/100"].Properties["@type"] != "relation" {
+		t.Errorf("unexpected properties %v", features["relation/100"].Properties)
+	}
+
+	data, err := json.Marshal(fc)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded FeatureCollection
+
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Features) != len(fc.Features) {
+		t.Errorf("failed to round trip the collection: %v", err)
+	}
+}
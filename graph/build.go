@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"fmt"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/helpers"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+// Builds the graph of a network downloaded with a preset query, e.g. GetPresetQueryByRadius with
+// JSON or XML output decoded by ExecuteResponse.
+//
+// Every way tagged highway or railway is split into edges at the nodes it shares with other ways
+// (and with itself) and at its ends, the nodes in between only contribute to the edge geometry.
+// Ways whose nodes are not all in the response are skipped. Each piece produces an edge in both
+// directions unless the way is oneway for the network type (see Oneway).
+//
+// Turn restriction relations applicable to the network type are added to the graph (see
+// ParseRestriction), relations that are malformed or exempt the network type are skipped.
+func Build(resp *ovp.Response, mode osm.PresetNetworkType) (*Graph, error) {
+	g := New(mode)
+
+	nodes := map[int64]*ovp.NodeElement{}
+	for _, n := range resp.Nodes() {
+		nodes[n.ID] = n
+	}
+
+	var ways []*ovp.WayElement
+	uses := map[int64]int{}
+
+	for _, w := range resp.Ways() {
+		if !isNetworkWay(w) || !hasAllNodes(w, nodes) {
+			continue
+		}
+
+		ways = append(ways, w)
+
+		for i, id := range w.Nodes {
+			uses[id]++
+			// Ends always split, counting them twice marks them as graph nodes
+			if i == 0 || i == len(w.Nodes)-1 {
+				uses[id]++
+			}
+		}
+	}
+
+	if len(ways) == 0 && len(resp.Ways()) > 0 {
+		return nil, fmt.Errorf("no way in the response has all its nodes, the query must recurse down to nodes (>;)")
+	}
+
+	for _, w := range ways {
+		direction := Oneway(w.Tags, mode)
+		start := 0
+
+		for i := 1; i < len(w.Nodes); i++ {
+			if uses[w.Nodes[i]] < 2 {
+				continue
+			}
+
+			ids := w.Nodes[start : i+1]
+			start = i
+
+			geometry := make([][]float64, len(ids))
+			for j, id := range ids {
+				n := nodes[id]
+				geometry[j] = []float64{n.Lon, n.Lat}
+			}
+
+			from, to := addNetworkNode(g, nodes[ids[0]]), addNetworkNode(g, nodes[ids[len(ids)-1]])
+			length := helpers.LineLength(geometry)
+
+			if direction >= 0 {
+				g.AddEdge(&Edge{
+					From:     from.ID,
+					To:       to.ID,
+					WayID:    w.ID,
+					WayIDs:   []int64{w.ID},
+					Geometry: geometry,
+					Length:   length,
+					Speed:    Speed(w.Tags, mode, false),
+					Oneway:   direction == 1,
+					Tags:     w.Tags,
+				})
+			}
+
+			if direction <= 0 {
+				reversed := append([][]float64{}, geometry...)
+				helpers.ReverseCoordinates(reversed)
+
+				g.AddEdge(&Edge{
+					From:     to.ID,
+					To:       from.ID,
+					WayID:    w.ID,
+					WayIDs:   []int64{w.ID},
+					Geometry: reversed,
+					Length:   length,
+					Speed:    Speed(w.Tags, mode, true),
+					Oneway:   direction == -1,
+					Reversed: true,
+					Tags:     w.Tags,
+				})
+			}
+		}
+	}
+
+	for _, rel := range resp.Relations() {
+		if r, ok := ParseRestriction(rel, mode); ok {
+			g.AddRestriction(r)
+		}
+	}
+
+	return g, nil
+}
+
+func addNetworkNode(g *Graph, n *ovp.NodeElement) *Node {
+	if node, ok := g.Nodes[n.ID]; ok {
+		return node
+	}
+	node := &Node{ID: n.ID, Lat: n.Lat, Lon: n.Lon, Tags: n.Tags}
+	g.AddNode(node)
+	return node
+}
+
+func isNetworkWay(w *ovp.WayElement) bool {
+	return len(w.Nodes) >= 2 && (w.Tags["highway"] != "" || w.Tags["railway"] != "")
+}
+
+func hasAllNodes(w *ovp.WayElement, nodes map[int64]*ovp.NodeElement) bool {
+	for _, id := range w.Nodes {
+		if _, ok := nodes[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the direction a way with these tags can be travelled in by the network type: 1 only
+// along its node order, -1 only against it and 0 both ways.
+//
+//   - oneway=yes|true|1 is 1, oneway=-1|reverse is -1, oneway=no|reversible|alternating is 0
+//   - junction=roundabout|circular and highway=motorway imply oneway=yes unless tagged otherwise
+//   - Walk ignores oneway, only oneway:foot applies
+//   - Bike honours oneway:bicycle over oneway, and cycleway=opposite* allows riding both ways
+func Oneway(tags map[string]string, mode osm.PresetNetworkType) int {
+	switch mode {
+	case osm.Walk:
+		return onewayValue(tags["oneway:foot"])
+
+	case osm.Bike:
+		if v, ok := tags["oneway:bicycle"]; ok {
+			return onewayValue(v)
+		}
+		for _, k := range []string{"cycleway", "cycleway:left", "cycleway:right", "cycleway:both"} {
+			switch tags[k] {
+			case "opposite", "opposite_lane", "opposite_track", "opposite_share_busway":
+				return 0
+			}
+		}
+	}
+
+	if v, ok := tags["oneway"]; ok {
+		return onewayValue(v)
+	}
+
+	switch {
+	case tags["junction"] == "roundabout" || tags["junction"] == "circular":
+		return 1
+	case tags["highway"] == "motorway":
+		return 1
+	}
+
+	return 0
+}
+
+func onewayValue(v string) int {
+	switch v {
+	case "yes", "true", "1":
+		return 1
+	case "-1", "reverse":
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+// Two ways crossing at node 3, way 10 is oneway and way 11 is a two-way street:
+//
+//	1 --- 2 --- 3 --- 4   (way 10, oneway=yes)
+//	            |
+//	            5         (way 11, 3 -> 5 -> 6)
+//	            |
+//	            6
+const testNetwork = `{
+  "elements": [
+    {"type": "node", "id": 1, "lat": 0, "lon": 0.000},
+    {"type": "node", "id": 2, "lat": 0, "lon": 0.001},
+    {"type": "node", "id": 3, "lat": 0, "lon": 0.002, "tags": {"highway": "traffic_signals"}},
+    {"type": "node", "id": 4, "lat": 0, "lon": 0.003},
+    {"type": "node", "id": 5, "lat": -0.001, "lon": 0.002},
+    {"type": "node", "id": 6, "lat": -0.002, "lon": 0.002},
+    {"type": "way", "id": 10, "nodes": [1, 2, 3, 4], "tags": {"highway": "primary", "oneway": "yes", "oneway:bicycle": "no"}},
+    {"type": "way", "id": 11, "nodes": [3, 5, 6], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 12, "nodes": [4, 6], "tags": {"building": "yes"}}
+  ]
+}`
+
+func buildTestGraph(t *testing.T, mode osm.PresetNetworkType, network string) *Graph {
+	resp, err := ovp.DecodeJSON(strings.NewReader(network))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g, err := Build(resp, mode)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return g
+}
+
+func TestBuild(t *testing.T) {
+	var testCases = []struct {
+		mode  osm.PresetNetworkType
+		nodes int
+		edges int
+	}{
+		{osm.Drive, 4, 4},
+		{osm.Walk, 4, 6},
+		{osm.Bike, 4, 6},
+	}
+
+	for _, test := range testCases {
+		g := buildTestGraph(t, test.mode, testNetwork)
+
+		if len(g.Nodes) != test.nodes {
+			t.Errorf("%v: expected %d nodes, got %d", test.mode, test.nodes, len(g.Nodes))
+		}
+
+		if len(g.Edges) != test.edges {
+			t.Errorf("%v: expected %d edges, got %d", test.mode, test.edges, len(g.Edges))
+		}
+
+		if _, ok := g.Nodes[2]; ok {
+			t.Errorf("%v: interstitial node 2 should not be a graph node", test.mode)
+		}
+	}
+
+	g := buildTestGraph(t, osm.Drive, testNetwork)
+
+	if len(g.OutEdges(3)) != 2 || len(g.InEdges(3)) != 2 {
+		t.Errorf("expected 2 edges in and out of node 3, got %d and %d", len(g.InEdges(3)), len(g.OutEdges(3)))
+	}
+
+	e := g.OutEdges(1)[0]
+
+	if e.To != 3 || e.WayID != 10 || !e.Oneway || len(e.Geometry) != 3 || e.Tags["highway"] != "primary" {
+		t.Errorf("unexpected edge %+v", e)
+	}
+
+	// 0.002 degrees of longitude at the equator
+	if math.Abs(e.Length-222.39) > 0.1 {
+		t.Errorf("expected length of about 222.39m, got %v", e.Length)
+	}
+
+	if g.Nodes[3].Tags["highway"] != "traffic_signals" {
+		t.Errorf("expected node tags to be kept, got %v", g.Nodes[3].Tags)
+	}
+}
+
+func TestOneway(t *testing.T) {
+	var testCases = []struct {
+		tags      map[string]string
+		mode      osm.PresetNetworkType
+		direction int
+	}{
+		{map[string]string{"highway": "residential"}, osm.Drive, 0},
+		{map[string]string{"oneway": "yes"}, osm.Drive, 1},
+		{map[string]string{"oneway": "-1"}, osm.Drive, -1},
+		{map[string]string{"oneway": "reversible"}, osm.Drive, 0},
+		{map[string]string{"junction": "roundabout"}, osm.Drive, 1},
+		{map[string]string{"junction": "roundabout", "oneway": "no"}, osm.Drive, 0},
+		{map[string]string{"highway": "motorway"}, osm.Drive, 1},
+		{map[string]string{"oneway": "yes"}, osm.Walk, 0},
+		{map[string]string{"oneway": "yes", "oneway:foot": "yes"}, osm.Walk, 1},
+		{map[string]string{"oneway": "yes"}, osm.Bike, 1},
+		{map[string]string{"oneway": "yes", "oneway:bicycle": "no"}, osm.Bike, 0},
+		{map[string]string{"oneway": "yes", "cycleway": "opposite_lane"}, osm.Bike, 0},
+		{map[string]string{"junction": "roundabout"}, osm.Bike, 1},
+	}
+
+	for _, test := range testCases {
+		if direction := Oneway(test.tags, test.mode); direction != test.direction {
+			t.Errorf("%v %v: expected %d, got %d", test.mode, test.tags, test.direction, direction)
+		}
+	}
+}
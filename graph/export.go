@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/captchanjack/osmdata/geojson"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// Writes the nodes and edges of the graph as CSV with a header row, edges start with their from and
+// to node ids so they load as an edge list in igraph or NetworkX. OSM tags are a JSON object in the
+// tags column and the edge geometry is WKT.
+//
+//	nodes: id,lat,lon,tags
+//	edges: from,to,id,way_id,way_ids,length,speed_kph,travel_time,oneway,reversed,geometry,tags
+func (g *Graph) WriteCSV(nodes io.Writer, edges io.Writer) error {
+	w := csv.NewWriter(nodes)
+	w.Write([]string{"id", "lat", "lon", "tags"})
+
+	for _, id := range g.NodeIDs() {
+		n := g.Nodes[id]
+		tags, err := encodeTags(n.Tags)
+
+		if err != nil {
+			return err
+		}
+
+		w.Write([]string{strconv.FormatInt(id, 10), formatFloat(n.Lat), formatFloat(n.Lon), tags})
+	}
+
+	if w.Flush(); w.Error() != nil {
+		return fmt.Errorf("failed to write nodes: %w", w.Error())
+	}
+
+	w = csv.NewWriter(edges)
+	w.Write([]string{"from", "to", "id", "way_id", "way_ids", "length", "speed_kph", "travel_time", "oneway", "reversed", "geometry", "tags"})
+
+	for _, e := range g.EdgeList() {
+		tags, err := encodeTags(e.Tags)
+
+		if err != nil {
+			return err
+		}
+
+		w.Write([]string{
+			strconv.FormatInt(e.From, 10),
+			strconv.FormatInt(e.To, 10),
+			strconv.Itoa(e.ID),
+			strconv.FormatInt(e.WayID, 10),
+			helpers.JoinArrInt64(e.WayIDs, ";"),
+			formatFloat(e.Length),
+			formatFloat(e.Speed),
+			formatFloat(TravelTime(e)),
+			strconv.FormatBool(e.Oneway),
+			strconv.FormatBool(e.Reversed),
+			FormatWKT(e.Geometry),
+			tags,
+		})
+	}
+
+	if w.Flush(); w.Error() != nil {
+		return fmt.Errorf("failed to write edges: %w", w.Error())
+	}
+
+	return nil
+}
+
+// Writes the nodes and edges of the graph as CSV files on disk, e.g. ./nodes.csv and ./edges.csv
+func (g *Graph) WriteCSVFiles(nodesFilename string, edgesFilename string) error {
+	nodes, err := os.Create(nodesFilename)
+
+	if err != nil {
+		return err
+	}
+
+	defer nodes.Close()
+
+	edges, err := os.Create(edgesFilename)
+
+	if err != nil {
+		return err
+	}
+
+	defer edges.Close()
+
+	if err := g.WriteCSV(nodes, edges); err != nil {
+		return err
+	}
+
+	if err := nodes.Close(); err != nil {
+		return err
+	}
+
+	return edges.Close()
+}
+
+// Returns the nodes as Point features and the edges as LineString features, e.g. as two layers in
+// QGIS. Tags become properties, along with "@id" and the other attributes prefixed with "@".
+func (g *Graph) GeoJSON() (nodes *geojson.FeatureCollection, edges *geojson.FeatureCollection) {
+	nodes, edges = geojson.NewFeatureCollection(), geojson.NewFeatureCollection()
+
+	for _, id := range g.NodeIDs() {
+		n := g.Nodes[id]
+		properties := map[string]interface{}{}
+
+		for k, v := range n.Tags {
+			properties[k] = v
+		}
+
+		properties["@id"] = n.ID
+
+		nodes.Append(geojson.NewFeature(strconv.FormatInt(n.ID, 10), geojson.NewPoint([]float64{n.Lon, n.Lat}), properties))
+	}
+
+	for _, e := range g.EdgeList() {
+		properties := map[string]interface{}{}
+
+		for k, v := range e.Tags {
+			properties[k] = v
+		}
+
+		properties["@id"] = e.ID
+		properties["@from"] = e.From
+		properties["@to"] = e.To
+		properties["@way_id"] = e.WayID
+		properties["@way_ids"] = e.WayIDs
+		properties["@length"] = e.Length
+		properties["@speed_kph"] = e.Speed
+		properties["@oneway"] = e.Oneway
+		properties["@reversed"] = e.Reversed
+
+		if t := TravelTime(e); !math.IsInf(t, 1) {
+			properties["@travel_time"] = t
+		}
+
+		edges.Append(geojson.NewFeature(strconv.Itoa(e.ID), geojson.NewLineString(e.Geometry), properties))
+	}
+
+	return nodes, edges
+}
+
+// Writes the node and edge GeoJSON layers to files on disk, e.g. ./nodes.geojson and ./edges.geojson
+func (g *Graph) WriteGeoJSONFiles(nodesFilename string, edgesFilename string) error {
+	nodes, edges := g.GeoJSON()
+
+	if err := nodes.WriteFile(nodesFilename); err != nil {
+		return err
+	}
+
+	return edges.WriteFile(edgesFilename)
+}
+
+func encodeTags(tags map[string]string) (string, error) {
+	if len(tags) == 0 {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(tags)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tags: %w", err)
+	}
+
+	return string(data), nil
+}
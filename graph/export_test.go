@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/geojson"
+)
+
+func TestGraphML(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testRestrictionNetwork)
+	g.Nodes[3].Tags = map[string]string{"highway": "traffic_signals"}
+	g.Simplify()
+
+	filename := filepath.Join(t.TempDir(), "network.graphml")
+
+	if err := g.WriteGraphMLFile(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	read, err := ReadGraphMLFile(filename)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if read.Mode != g.Mode {
+		t.Errorf("expected mode %v, got %v", g.Mode, read.Mode)
+	}
+
+	if !reflect.DeepEqual(read.Nodes, g.Nodes) {
+		t.Errorf("expected nodes %v, got %v", g.Nodes, read.Nodes)
+	}
+
+	if !reflect.DeepEqual(read.EdgeList(), g.EdgeList()) {
+		t.Errorf("expected edges %v, got %v", g.EdgeList(), read.EdgeList())
+	}
+
+	if !reflect.DeepEqual(read.Restrictions, g.Restrictions) {
+		t.Errorf("expected restrictions %v, got %v", g.Restrictions, read.Restrictions)
+	}
+
+	// Restrictions still apply to the reloaded graph
+	p, err := read.ShortestPath(1, 4, Distance)
+
+	if err != nil || !reflect.DeepEqual(p.Nodes, []int64{1, 3, 5, 3, 4}) {
+		t.Errorf("expected restricted path [1 3 5 3 4], got %v (%v)", p, err)
+	}
+
+	// A new edge continues the ids
+	if e := read.AddEdge(&Edge{From: 1, To: 4}); e.ID != g.nextEdgeID {
+		t.Errorf("expected new edge id %d, got %d", g.nextEdgeID, e.ID)
+	}
+}
+
+func TestReadGraphMLErrors(t *testing.T) {
+	var testCases = []string{
+		`<graphml`,
+		`<graphml><graph><node id="a"/></graph></graphml>`,
+		`<graphml><graph><node id="1"/><edge source="1" target="2"/></graph></graphml>`,
+		`<graphml><key id="d0" for="node" attr.name="lat" attr.type="double"/><graph><node id="1"><data key="d0">north</data></node></graph></graphml>`,
+	}
+
+	for _, test := range testCases {
+		if _, err := ReadGraphML(strings.NewReader(test)); err == nil {
+			t.Errorf("%v: expected error", test)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testNetwork)
+
+	var nodes, edges bytes.Buffer
+
+	if err := g.WriteCSV(&nodes, &edges); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nodeRows, err := csv.NewReader(&nodes).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	edgeRows, err := csv.NewReader(&edges).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(nodeRows) != len(g.Nodes)+1 || len(edgeRows) != len(g.Edges)+1 {
+		t.Fatalf("expected %d node and %d edge rows, got %d and %d", len(g.Nodes)+1, len(g.Edges)+1, len(nodeRows), len(edgeRows))
+	}
+
+	if !reflect.DeepEqual(nodeRows[2], []string{"3", "0", "0.002", `{"highway":"traffic_signals"}`}) {
+		t.Errorf("unexpected node row %v", nodeRows[2])
+	}
+
+	if row := edgeRows[1]; row[0] != "1" || row[1] != "3" || row[3] != "10" || row[10] != "LINESTRING (0 0, 0.001 0, 0.002 0)" {
+		t.Errorf("unexpected edge row %v", row)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testNetwork)
+	nodes, edges := g.GeoJSON()
+
+	if len(nodes.Features) != len(g.Nodes) || len(edges.Features) != len(g.Edges) {
+		t.Fatalf("expected %d nodes and %d edges, got %d and %d", len(g.Nodes), len(g.Edges), len(nodes.Features), len(edges.Features))
+	}
+
+	e := edges.Features[0]
+
+	if e.Geometry.Type != geojson.LineString || e.Properties["highway"] != "primary" || e.Properties["@way_id"] != int64(10) {
+		t.Errorf("unexpected edge feature %v", e)
+	}
+
+	if n := nodes.Features[0]; n.Geometry.Type != geojson.Point || n.Properties["@id"] != int64(1) {
+		t.Errorf("unexpected node feature %v", n)
+	}
+}
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"sort"
+
+	osm "github.com/captchanjack/osmdata"
+)
+
+// Node is an intersection or dead end of the network
+type Node struct {
+	ID   int64 // OSM node id
+	Lat  float64
+	Lon  float64
+	Tags map[string]string
+}
+
+// Edge is a directed piece of an OSM way between two nodes, ways that can be travelled in both
+// directions produce one edge per direction
+type Edge struct {
+	ID       int               // Unique within the graph
+	From     int64             // Id of the start node
+	To       int64             // Id of the end node
+	WayID    int64             // Id of the OSM way the edge is part of
+	WayIDs   []int64           // Ids of every way the edge follows in order, several once merged by Simplify
+	Geometry [][]float64       // [lon, lat] coordinates from From to To, including both ends
+	Length   float64           // Length in metres
+	Speed    float64           // Travel speed in km/h for the network type, see Speed
+	Oneway   bool              // The way can only be travelled in this direction
+	Reversed bool              // The edge runs against the node order of the way
+	Tags     map[string]string // Tags of the way, shared by all edges of the way, combined once merged by Simplify
+}
+
+// Graph is a directed multigraph of a network, parallel edges between the same nodes are allowed
+type Graph struct {
+	Mode             osm.PresetNetworkType // Network type the graph was built for
+	Nodes            map[int64]*Node
+	Edges            map[int]*Edge
+	Restrictions     []*Restriction // Turn restrictions applicable to the network type, see Turn
+	out              map[int64][]*Edge
+	in               map[int64][]*Edge
+	restrictionsFrom map[int64][]*Restriction
+	nextEdgeID       int
+}
+
+// Returns a pointer to a new empty Graph for the given network type
+func New(mode osm.PresetNetworkType) *Graph {
+	g := new(Graph)
+	g.Mode = mode
+	g.Nodes = map[int64]*Node{}
+	g.Edges = map[int]*Edge{}
+	g.out = map[int64][]*Edge{}
+	g.in = map[int64][]*Edge{}
+	return g
+}
+
+// Adds a node, replacing any node with the same id
+func (g *Graph) AddNode(n *Node) {
+	g.Nodes[n.ID] = n
+}
+
+// Adds an edge between existing nodes and assigns it an id, returns the edge
+func (g *Graph) AddEdge(e *Edge) *Edge {
+	e.ID = g.nextEdgeID
+	g.nextEdgeID++
+	g.Edges[e.ID] = e
+	g.out[e.From] = append(g.out[e.From], e)
+	g.in[e.To] = append(g.in[e.To], e)
+	return e
+}
+
+// Removes an edge, does nothing if the edge does not exist
+func (g *Graph) RemoveEdge(id int) {
+	e, ok := g.Edges[id]
+	if !ok {
+		return
+	}
+	delete(g.Edges, id)
+	g.out[e.From] = removeEdge(g.out[e.From], id)
+	g.in[e.To] = removeEdge(g.in[e.To], id)
+}
+
+// Removes a node and every edge incident to it
+func (g *Graph) RemoveNode(id int64) {
+	for _, e := range append(g.OutEdges(id), g.InEdges(id)...) {
+		g.RemoveEdge(e.ID)
+	}
+	delete(g.Nodes, id)
+	delete(g.out, id)
+	delete(g.in, id)
+}
+
+// Returns the edges leaving a node, the slice must not be modified
+func (g *Graph) OutEdges(id int64) []*Edge {
+	return g.out[id]
+}
+
+// Returns the edges entering a node, the slice must not be modified
+func (g *Graph) InEdges(id int64) []*Edge {
+	return g.in[id]
+}
+
+// Returns every edge ordered by id
+func (g *Graph) EdgeList() []*Edge {
+	edges := make([]*Edge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges
+}
+
+// Returns every node id in ascending order
+func (g *Graph) NodeIDs() []int64 {
+	ids := make([]int64, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Returns a new graph of the given nodes and the edges between them, keeping the ids of the edges
+// and every turn restriction. Nodes and edges are shared with g, the ids not in g are ignored.
+func (g *Graph) Subgraph(ids []int64) *Graph {
+	return g.subgraph(ids, nil)
+}
+
+// Same as Subgraph but only keeps the edges for which keep returns true, all of them if keep is nil
+func (g *Graph) subgraph(ids []int64, keep func(e *Edge) bool) *Graph {
+	sub := New(g.Mode)
+
+	for _, id := range ids {
+		if n, ok := g.Nodes[id]; ok {
+			sub.AddNode(n)
+		}
+	}
+
+	for _, e := range g.EdgeList() {
+		_, from := sub.Nodes[e.From]
+		_, to := sub.Nodes[e.To]
+
+		if from && to && (keep == nil || keep(e)) {
+			sub.Edges[e.ID] = e
+			sub.out[e.From] = append(sub.out[e.From], e)
+			sub.in[e.To] = append(sub.in[e.To], e)
+		}
+	}
+
+	sub.nextEdgeID = g.nextEdgeID
+
+	for _, r := range g.Restrictions {
+		sub.AddRestriction(r)
+	}
+
+	return sub
+}
+
+func removeEdge(edges []*Edge, id int) []*Edge {
+	for i, e := range edges {
+		if e.ID == id {
+			return append(edges[:i:i], edges[i+1:]...)
+		}
+	}
+	return edges
+}
@@ -0,0 +1,445 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// Prefix of the GraphML attribute names holding OSM tags, e.g. tag:highway
+const graphMLTagPrefix = "tag:"
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr,omitempty"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// Typed attributes written for every graph, node and edge, in order
+var graphMLKeys = []graphMLKey{
+	{For: "graph", Name: "mode", Type: "string"},
+	{For: "graph", Name: "restrictions", Type: "string"},
+	{For: "node", Name: "lat", Type: "double"},
+	{For: "node", Name: "lon", Type: "double"},
+	{For: "edge", Name: "way_id", Type: "long"},
+	{For: "edge", Name: "way_ids", Type: "string"},
+	{For: "edge", Name: "length", Type: "double"},
+	{For: "edge", Name: "speed_kph", Type: "double"},
+	{For: "edge", Name: "travel_time", Type: "double"},
+	{For: "edge", Name: "oneway", Type: "boolean"},
+	{For: "edge", Name: "reversed", Type: "boolean"},
+	{For: "edge", Name: "geometry", Type: "string"},
+}
+
+// Writes the graph as GraphML, readable by NetworkX, igraph and ReadGraphML. Node coordinates,
+// edge attributes and the edge geometry as WKT are typed keys, OSM tags are string keys named
+// tag:<key> and turn restrictions are stored as JSON in the restrictions graph attribute.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{XMLNS: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Graph = graphMLGraph{ID: "G", EdgeDefault: "directed"}
+
+	ids := map[string]string{}
+	addKey := func(k graphMLKey) {
+		k.ID = fmt.Sprintf("d%d", len(doc.Keys))
+		ids[k.For+"/"+k.Name] = k.ID
+		doc.Keys = append(doc.Keys, k)
+	}
+
+	for _, k := range graphMLKeys {
+		addKey(k)
+	}
+
+	nodeIDs, edges := g.NodeIDs(), g.EdgeList()
+
+	for _, kind := range []string{"node", "edge"} {
+		tags := map[string]string{}
+		if kind == "node" {
+			for _, n := range g.Nodes {
+				for k := range n.Tags {
+					tags[k] = ""
+				}
+			}
+		} else {
+			for _, e := range edges {
+				for k := range e.Tags {
+					tags[k] = ""
+				}
+			}
+		}
+
+		for _, k := range sortedKeys(tags) {
+			addKey(graphMLKey{For: kind, Name: graphMLTagPrefix + k, Type: "string"})
+		}
+	}
+
+	restrictions, err := json.Marshal(g.Restrictions)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode restrictions: %w", err)
+	}
+
+	doc.Graph.Data = []graphMLData{
+		{ids["graph/mode"], string(g.Mode)},
+		{ids["graph/restrictions"], string(restrictions)},
+	}
+
+	for _, id := range nodeIDs {
+		n := g.Nodes[id]
+		node := graphMLNode{ID: strconv.FormatInt(id, 10)}
+		node.Data = append(node.Data,
+			graphMLData{ids["node/lat"], formatFloat(n.Lat)},
+			graphMLData{ids["node/lon"], formatFloat(n.Lon)},
+		)
+
+		for _, k := range sortedKeys(n.Tags) {
+			node.Data = append(node.Data, graphMLData{ids["node/"+graphMLTagPrefix+k], n.Tags[k]})
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, e := range edges {
+		edge := graphMLEdge{
+			ID:     strconv.Itoa(e.ID),
+			Source: strconv.FormatInt(e.From, 10),
+			Target: strconv.FormatInt(e.To, 10),
+		}
+
+		edge.Data = append(edge.Data,
+			graphMLData{ids["edge/way_id"], strconv.FormatInt(e.WayID, 10)},
+			graphMLData{ids["edge/way_ids"], helpers.JoinArrInt64(e.WayIDs, ";")},
+			graphMLData{ids["edge/length"], formatFloat(e.Length)},
+			graphMLData{ids["edge/speed_kph"], formatFloat(e.Speed)},
+			graphMLData{ids["edge/travel_time"], formatFloat(TravelTime(e))},
+			graphMLData{ids["edge/oneway"], strconv.FormatBool(e.Oneway)},
+			graphMLData{ids["edge/reversed"], strconv.FormatBool(e.Reversed)},
+			graphMLData{ids["edge/geometry"], FormatWKT(e.Geometry)},
+		)
+
+		for _, k := range sortedKeys(e.Tags) {
+			edge.Data = append(edge.Data, graphMLData{ids["edge/"+graphMLTagPrefix+k], e.Tags[k]})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode GraphML: %w", err)
+	}
+
+	return nil
+}
+
+// Writes the graph as GraphML to file on disk, e.g. ./network.graphml
+func (g *Graph) WriteGraphMLFile(filename string) error {
+	f, err := os.Create(filename)
+
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	if err := g.WriteGraphML(w); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Reads a graph written by WriteGraphML. Node and edge attributes other than those written by
+// WriteGraphML are ignored, edges without a numeric id are given a new one.
+func ReadGraphML(r io.Reader) (*Graph, error) {
+	var doc graphML
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode GraphML: %w", err)
+	}
+
+	names := map[string]string{}
+	for _, k := range doc.Keys {
+		names[k.ID] = k.Name
+	}
+
+	g := New("")
+
+	for _, d := range doc.Graph.Data {
+		switch names[d.Key] {
+		case "mode":
+			g.Mode = osm.PresetNetworkType(d.Value)
+		case "restrictions":
+			var restrictions []*Restriction
+			if err := json.Unmarshal([]byte(d.Value), &restrictions); err != nil {
+				return nil, fmt.Errorf("failed to decode restrictions: %w", err)
+			}
+			for _, r := range restrictions {
+				g.AddRestriction(r)
+			}
+		}
+	}
+
+	for _, node := range doc.Graph.Nodes {
+		id, err := strconv.ParseInt(node.ID, 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid node id '%s': %w", node.ID, err)
+		}
+
+		n := &Node{ID: id}
+
+		for _, d := range node.Data {
+			name := names[d.Key]
+
+			switch {
+			case name == "lat":
+				n.Lat, err = strconv.ParseFloat(d.Value, 64)
+			case name == "lon":
+				n.Lon, err = strconv.ParseFloat(d.Value, 64)
+			case strings.HasPrefix(name, graphMLTagPrefix):
+				if n.Tags == nil {
+					n.Tags = map[string]string{}
+				}
+				n.Tags[strings.TrimPrefix(name, graphMLTagPrefix)] = d.Value
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s of node %d: %w", name, id, err)
+			}
+		}
+
+		g.AddNode(n)
+	}
+
+	var unnumbered []*Edge
+
+	for _, edge := range doc.Graph.Edges {
+		e, err := parseGraphMLEdge(edge, names)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := g.Nodes[e.From]; !ok {
+			return nil, fmt.Errorf("edge '%s' starts at unknown node %d", edge.ID, e.From)
+		}
+
+		if _, ok := g.Nodes[e.To]; !ok {
+			return nil, fmt.Errorf("edge '%s' ends at unknown node %d", edge.ID, e.To)
+		}
+
+		id, err := strconv.Atoi(edge.ID)
+
+		if _, exists := g.Edges[id]; err != nil || id < 0 || exists {
+			unnumbered = append(unnumbered, e)
+			continue
+		}
+
+		e.ID = id
+		g.Edges[id] = e
+		g.out[e.From] = append(g.out[e.From], e)
+		g.in[e.To] = append(g.in[e.To], e)
+
+		if id >= g.nextEdgeID {
+			g.nextEdgeID = id + 1
+		}
+	}
+
+	for _, e := range unnumbered {
+		g.AddEdge(e)
+	}
+
+	return g, nil
+}
+
+// Reads a graph from a GraphML file on disk written by WriteGraphMLFile
+func ReadGraphMLFile(filename string) (*Graph, error) {
+	f, err := os.Open(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return ReadGraphML(bufio.NewReader(f))
+}
+
+func parseGraphMLEdge(edge graphMLEdge, names map[string]string) (*Edge, error) {
+	e := &Edge{}
+
+	var err error
+
+	if e.From, err = strconv.ParseInt(edge.Source, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid source of edge '%s': %w", edge.ID, err)
+	}
+
+	if e.To, err = strconv.ParseInt(edge.Target, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid target of edge '%s': %w", edge.ID, err)
+	}
+
+	for _, d := range edge.Data {
+		name := names[d.Key]
+
+		switch {
+		case name == "way_id":
+			e.WayID, err = strconv.ParseInt(d.Value, 10, 64)
+		case name == "way_ids":
+			e.WayIDs, err = parseWayIDs(d.Value)
+		case name == "length":
+			e.Length, err = strconv.ParseFloat(d.Value, 64)
+		case name == "speed_kph":
+			e.Speed, err = strconv.ParseFloat(d.Value, 64)
+		case name == "oneway":
+			e.Oneway, err = strconv.ParseBool(d.Value)
+		case name == "reversed":
+			e.Reversed, err = strconv.ParseBool(d.Value)
+		case name == "geometry":
+			e.Geometry, err = ParseWKT(d.Value)
+		case strings.HasPrefix(name, graphMLTagPrefix):
+			if e.Tags == nil {
+				e.Tags = map[string]string{}
+			}
+			e.Tags[strings.TrimPrefix(name, graphMLTagPrefix)] = d.Value
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s of edge '%s': %w", name, edge.ID, err)
+		}
+	}
+
+	return e, nil
+}
+
+// Returns a line of [lon, lat] coordinates as WKT, e.g. LINESTRING (144.93 -37.74, 144.94 -37.74)
+func FormatWKT(coordinates [][]float64) string {
+	points := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		points[i] = formatFloat(c[0]) + " " + formatFloat(c[1])
+	}
+	return "LINESTRING (" + strings.Join(points, ", ") + ")"
+}
+
+// Parses a WKT LINESTRING into [lon, lat] coordinates
+func ParseWKT(wkt string) ([][]float64, error) {
+	wkt = strings.TrimSpace(wkt)
+
+	if wkt == "" {
+		return nil, nil
+	}
+
+	body := strings.TrimSpace(strings.TrimPrefix(wkt, "LINESTRING"))
+
+	if len(body) == len(wkt) || !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+		return nil, fmt.Errorf("unsupported WKT geometry '%s'", wkt)
+	}
+
+	var coordinates [][]float64
+
+	for _, point := range strings.Split(body[1:len(body)-1], ",") {
+		fields := strings.Fields(point)
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid WKT point '%s'", point)
+		}
+
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		coordinates = append(coordinates, []float64{lon, lat})
+	}
+
+	return coordinates, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func parseWayIDs(s string) ([]int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var ids []int64
+
+	for _, v := range strings.Split(s, ";") {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
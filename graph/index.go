@@ -0,0 +1,439 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// Maximum number of entries in a node of the R-tree
+const indexNodeCapacity = 16
+
+// Index is an R-tree over the nodes and edge segments of a graph, answering nearest, within radius
+// and bounding box queries. It does not follow changes to the graph, build a new index after
+// modifying the graph.
+type Index struct {
+	graph      *Graph
+	projection *helpers.LocalProjection
+	nodes      *indexNode
+	segments   *indexNode
+}
+
+// EdgeProjection is a point projected onto the closest point of an edge
+type EdgeProjection struct {
+	Edge     *Edge
+	Point    []float64 // [lon, lat] of the closest point on the edge
+	Distance float64   // Metres from the point to the edge
+	Offset   float64   // Metres along the edge from its start to the closest point
+	segment  int
+}
+
+type indexRect struct {
+	minX, minY, maxX, maxY float64
+}
+
+// Entry of the R-tree, a node or a segment of an edge in leaves and a child in the other nodes
+type indexEntry struct {
+	rect    indexRect
+	child   *indexNode
+	node    *Node
+	edge    *Edge
+	segment int // Index of the first coordinate of the segment in the edge geometry
+}
+
+type indexNode struct {
+	rect    indexRect
+	entries []*indexEntry
+}
+
+// Returns a pointer to a new Index over the nodes and edges of the graph
+func NewIndex(g *Graph) *Index {
+	idx := new(Index)
+	idx.graph = g
+
+	lat := 0.0
+	for _, n := range g.Nodes {
+		lat += n.Lat
+	}
+	if len(g.Nodes) > 0 {
+		lat /= float64(len(g.Nodes))
+	}
+	idx.projection = helpers.NewLocalProjection(lat)
+
+	var nodes, segments []*indexEntry
+
+	for _, id := range g.NodeIDs() {
+		n := g.Nodes[id]
+		p := idx.project(n.Lon, n.Lat)
+		nodes = append(nodes, &indexEntry{rect: indexRect{p[0], p[1], p[0], p[1]}, node: n})
+	}
+
+	for _, e := range g.EdgeList() {
+		for i := 1; i < len(e.Geometry); i++ {
+			a := idx.projection.Project(e.Geometry[i-1])
+			b := idx.projection.Project(e.Geometry[i])
+			rect := indexRect{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Max(a[0], b[0]), math.Max(a[1], b[1])}
+			segments = append(segments, &indexEntry{rect: rect, edge: e, segment: i - 1})
+		}
+	}
+
+	idx.nodes = buildIndexNode(nodes)
+	idx.segments = buildIndexNode(segments)
+
+	return idx
+}
+
+// Returns the node nearest to the point, nil for an empty graph
+func (idx *Index) NearestNode(lat float64, lon float64) *Node {
+	if nodes := idx.NearestNodes(lat, lon, 1); len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// Returns up to k nodes nearest to the point, closest first
+func (idx *Index) NearestNodes(lat float64, lon float64, k int) []*Node {
+	var nodes []*Node
+
+	idx.nearest(idx.nodes, lat, lon, math.Inf(1), func(entry *indexEntry, distance float64) bool {
+		nodes = append(nodes, entry.node)
+		return len(nodes) >= k
+	})
+
+	return nodes
+}
+
+// Returns the nodes within radius metres of the point, closest first
+func (idx *Index) NodesWithin(lat float64, lon float64, radius float64) []*Node {
+	var nodes []*Node
+
+	idx.nearest(idx.nodes, lat, lon, radius, func(entry *indexEntry, distance float64) bool {
+		nodes = append(nodes, entry.node)
+		return false
+	})
+
+	return nodes
+}
+
+// Returns the nodes inside the bounding box, ordered by id
+func (idx *Index) NodesInBounds(minLat float64, minLon float64, maxLat float64, maxLon float64) []*Node {
+	var nodes []*Node
+
+	idx.search(idx.nodes, idx.bounds(minLat, minLon, maxLat, maxLon), func(entry *indexEntry) {
+		nodes = append(nodes, entry.node)
+	})
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// Returns the projection of the point onto the nearest edge, nil for a graph without edges. The two
+// edges of a way travelled in both directions are equally near, either may be returned.
+func (idx *Index) NearestEdge(lat float64, lon float64) *EdgeProjection {
+	if edges := idx.NearestEdges(lat, lon, 1); len(edges) > 0 {
+		return edges[0]
+	}
+	return nil
+}
+
+// Returns the projections of the point onto up to k nearest edges, closest first
+func (idx *Index) NearestEdges(lat float64, lon float64, k int) []*EdgeProjection {
+	var projections []*EdgeProjection
+	seen := map[int]bool{}
+
+	idx.nearest(idx.segments, lat, lon, math.Inf(1), func(entry *indexEntry, distance float64) bool {
+		// Segments come closest first, so the first segment of an edge is its closest
+		if !seen[entry.edge.ID] {
+			seen[entry.edge.ID] = true
+			projections = append(projections, idx.projectOnto(entry, lat, lon))
+		}
+		return len(projections) >= k
+	})
+
+	return projections
+}
+
+// Returns the projections of the point onto the edges within radius metres, closest first
+func (idx *Index) EdgesWithin(lat float64, lon float64, radius float64) []*EdgeProjection {
+	var projections []*EdgeProjection
+	seen := map[int]bool{}
+
+	idx.nearest(idx.segments, lat, lon, radius, func(entry *indexEntry, distance float64) bool {
+		if !seen[entry.edge.ID] {
+			seen[entry.edge.ID] = true
+			projections = append(projections, idx.projectOnto(entry, lat, lon))
+		}
+		return false
+	})
+
+	return projections
+}
+
+// Returns the edges with a segment crossing the bounding box, ordered by id
+func (idx *Index) EdgesInBounds(minLat float64, minLon float64, maxLat float64, maxLon float64) []*Edge {
+	var edges []*Edge
+	seen := map[int]bool{}
+	bounds := idx.bounds(minLat, minLon, maxLat, maxLon)
+
+	idx.search(idx.segments, bounds, func(entry *indexEntry) {
+		if seen[entry.edge.ID] {
+			return
+		}
+
+		a := idx.projection.Project(entry.edge.Geometry[entry.segment])
+		b := idx.projection.Project(entry.edge.Geometry[entry.segment+1])
+
+		if segmentIntersectsRect(a, b, bounds) {
+			seen[entry.edge.ID] = true
+			edges = append(edges, entry.edge)
+		}
+	})
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges
+}
+
+func (idx *Index) project(lon float64, lat float64) []float64 {
+	return idx.projection.Project([]float64{lon, lat})
+}
+
+func (idx *Index) bounds(minLat float64, minLon float64, maxLat float64, maxLon float64) indexRect {
+	min, max := idx.project(minLon, minLat), idx.project(maxLon, maxLat)
+	return indexRect{min[0], min[1], max[0], max[1]}
+}
+
+// Returns the projection of the point onto the segment of the entry
+func (idx *Index) projectOnto(entry *indexEntry, lat float64, lon float64) *EdgeProjection {
+	geometry := entry.edge.Geometry
+	a, b := geometry[entry.segment], geometry[entry.segment+1]
+
+	t := segmentFraction(idx.project(lon, lat), idx.projection.Project(a), idx.projection.Project(b))
+	point := []float64{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+
+	offset := helpers.LineLength(geometry[:entry.segment+1]) + helpers.Haversine(a[1], a[0], point[1], point[0])
+
+	return &EdgeProjection{
+		Edge:     entry.edge,
+		Point:    point,
+		Distance: helpers.Haversine(lat, lon, point[1], point[0]),
+		Offset:   math.Min(offset, entry.edge.Length),
+		segment:  entry.segment,
+	}
+}
+
+// Calls found with every leaf entry intersecting the rectangle
+func (idx *Index) search(n *indexNode, rect indexRect, found func(entry *indexEntry)) {
+	if n == nil || !n.rect.intersects(rect) {
+		return
+	}
+
+	for _, entry := range n.entries {
+		if !entry.rect.intersects(rect) {
+			continue
+		}
+
+		if entry.child != nil {
+			idx.search(entry.child, rect, found)
+		} else {
+			found(entry)
+		}
+	}
+}
+
+// Calls found with every leaf entry within limit metres of the point, closest first, until found
+// returns true
+func (idx *Index) nearest(root *indexNode, lat float64, lon float64, limit float64, found func(entry *indexEntry, distance float64) bool) {
+	if root == nil {
+		return
+	}
+
+	p := idx.project(lon, lat)
+	queue := &indexQueue{{distance: root.rect.distance(p), entry: &indexEntry{rect: root.rect, child: root}}}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(indexQueueItem)
+
+		if item.distance > limit {
+			return
+		}
+
+		if item.exact {
+			if found(item.entry, item.distance) {
+				return
+			}
+			continue
+		}
+
+		for _, entry := range item.entry.child.entries {
+			switch {
+			case entry.child != nil:
+				heap.Push(queue, indexQueueItem{distance: entry.rect.distance(p), entry: entry})
+			case entry.node != nil:
+				heap.Push(queue, indexQueueItem{distance: entry.rect.distance(p), entry: entry, exact: true})
+			default:
+				a := idx.projection.Project(entry.edge.Geometry[entry.segment])
+				b := idx.projection.Project(entry.edge.Geometry[entry.segment+1])
+				t := segmentFraction(p, a, b)
+				d := math.Hypot(p[0]-(a[0]+t*(b[0]-a[0])), p[1]-(a[1]+t*(b[1]-a[1])))
+				heap.Push(queue, indexQueueItem{distance: d, entry: entry, exact: true})
+			}
+		}
+	}
+}
+
+type indexQueueItem struct {
+	distance float64
+	entry    *indexEntry
+	exact    bool // The distance is to the leaf entry itself rather than a bounding rectangle
+}
+
+type indexQueue []indexQueueItem
+
+func (q indexQueue) Len() int { return len(q) }
+func (q indexQueue) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	// Expand rectangles before reporting entries at the same distance, and report those by id
+	if q[i].exact != q[j].exact {
+		return !q[i].exact
+	}
+	return q[i].entry.id() < q[j].entry.id()
+}
+func (q indexQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *indexQueue) Push(x interface{}) {
+	*q = append(*q, x.(indexQueueItem))
+}
+
+func (q *indexQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func (e *indexEntry) id() int64 {
+	switch {
+	case e.node != nil:
+		return e.node.ID
+	case e.edge != nil:
+		return int64(e.edge.ID)
+	}
+	return 0
+}
+
+// Builds the tree bottom up with sort-tile-recursive packing, returns nil if there are no entries
+func buildIndexNode(entries []*indexEntry) *indexNode {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for {
+		var level []*indexEntry
+
+		leaves := int(math.Ceil(float64(len(entries)) / indexNodeCapacity))
+		slabs := int(math.Ceil(math.Sqrt(float64(leaves))))
+		slabSize := slabs * indexNodeCapacity
+
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].rect.centerX() < entries[j].rect.centerX() })
+
+		for i := 0; i < len(entries); i += slabSize {
+			slab := entries[i:minInt(i+slabSize, len(entries))]
+			sort.SliceStable(slab, func(i, j int) bool { return slab[i].rect.centerY() < slab[j].rect.centerY() })
+
+			for j := 0; j < len(slab); j += indexNodeCapacity {
+				n := &indexNode{entries: slab[j:minInt(j+indexNodeCapacity, len(slab))]}
+				n.rect = n.entries[0].rect
+				for _, entry := range n.entries[1:] {
+					n.rect = n.rect.union(entry.rect)
+				}
+				level = append(level, &indexEntry{rect: n.rect, child: n})
+			}
+		}
+
+		if len(level) == 1 {
+			return level[0].child
+		}
+
+		entries = level
+	}
+}
+
+func (r indexRect) union(o indexRect) indexRect {
+	return indexRect{math.Min(r.minX, o.minX), math.Min(r.minY, o.minY), math.Max(r.maxX, o.maxX), math.Max(r.maxY, o.maxY)}
+}
+
+func (r indexRect) intersects(o indexRect) bool {
+	return r.minX <= o.maxX && o.minX <= r.maxX && r.minY <= o.maxY && o.minY <= r.maxY
+}
+
+func (r indexRect) contains(p []float64) bool {
+	return r.minX <= p[0] && p[0] <= r.maxX && r.minY <= p[1] && p[1] <= r.maxY
+}
+
+// Returns the distance from the point to the closest point of the rectangle
+func (r indexRect) distance(p []float64) float64 {
+	dx := math.Max(0, math.Max(r.minX-p[0], p[0]-r.maxX))
+	dy := math.Max(0, math.Max(r.minY-p[1], p[1]-r.maxY))
+	return math.Hypot(dx, dy)
+}
+
+func (r indexRect) centerX() float64 { return (r.minX + r.maxX) / 2 }
+func (r indexRect) centerY() float64 { return (r.minY + r.maxY) / 2 }
+
+// Returns the fraction along the segment from a to b of the point closest to p
+func segmentFraction(p []float64, a []float64, b []float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	length := dx*dx + dy*dy
+
+	if length == 0 {
+		return 0
+	}
+
+	return math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/length))
+}
+
+// Reports whether the segment from a to b crosses the rectangle
+func segmentIntersectsRect(a []float64, b []float64, r indexRect) bool {
+	if r.contains(a) || r.contains(b) {
+		return true
+	}
+
+	// Clip the segment against the rectangle (Liang-Barsky)
+	t0, t1 := 0.0, 1.0
+	dx, dy := b[0]-a[0], b[1]-a[1]
+
+	for _, c := range [][2]float64{{-dx, a[0] - r.minX}, {dx, r.maxX - a[0]}, {-dy, a[1] - r.minY}, {dy, r.maxY - a[1]}} {
+		p, q := c[0], c[1]
+
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+
+		if t0 > t1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+func nodeIDs(nodes []*Node) []int64 {
+	ids := []int64{}
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestIndexNodes(t *testing.T) {
+	idx := NewIndex(buildTestGraph(t, osm.Drive, testNetwork))
+
+	if n := idx.NearestNode(0.0001, 0.0021); n == nil || n.ID != 3 {
+		t.Errorf("expected nearest node 3, got %v", n)
+	}
+
+	if ids := nodeIDs(idx.NearestNodes(0, 0.0026, 2)); !reflect.DeepEqual(ids, []int64{4, 3}) {
+		t.Errorf("expected nearest nodes [4 3], got %v", ids)
+	}
+
+	if ids := nodeIDs(idx.NodesWithin(0, 0, 250)); !reflect.DeepEqual(ids, []int64{1, 3}) {
+		t.Errorf("expected nodes within 250 metres [1 3], got %v", ids)
+	}
+
+	if ids := nodeIDs(idx.NodesInBounds(-0.003, 0.0015, 0.001, 0.0025)); !reflect.DeepEqual(ids, []int64{3, 6}) {
+		t.Errorf("expected nodes in bounds [3 6], got %v", ids)
+	}
+
+	if n := NewIndex(New(osm.Drive)).NearestNode(0, 0); n != nil {
+		t.Errorf("expected no node in an empty graph, got %v", n)
+	}
+}
+
+func TestIndexEdges(t *testing.T) {
+	idx := NewIndex(buildTestGraph(t, osm.Drive, testNetwork))
+
+	p := idx.NearestEdge(0.0005, 0.0005)
+	expected := helpers.Haversine(0, 0, 0, 0.0005)
+
+	if p == nil || p.Edge.WayID != 10 || p.Edge.From != 1 {
+		t.Fatalf("expected nearest edge from 1 on way 10, got %v", p)
+	}
+
+	if math.Abs(p.Point[0]-0.0005) > 1e-12 || p.Point[1] != 0 {
+		t.Errorf("expected projected point [0.0005 0], got %v", p.Point)
+	}
+
+	if math.Abs(p.Offset-expected) > 1e-6 || math.Abs(p.Distance-helpers.Haversine(0.0005, 0.0005, 0, 0.0005)) > 1e-6 {
+		t.Errorf("expected offset %v, got %v and distance %v", expected, p.Offset, p.Distance)
+	}
+
+	edges := idx.NearestEdges(-0.001, 0.0025, 2)
+	if len(edges) != 2 || edges[0].Edge.WayID != 11 || edges[1].Edge.WayID != 11 {
+		t.Errorf("expected both edges of way 11, got %v", edges)
+	}
+
+	if within := idx.EdgesWithin(-0.001, 0.0025, 100); len(within) != 2 {
+		t.Errorf("expected 2 edges within 100 metres, got %d", len(within))
+	}
+
+	// Crosses the segment from 3 to 5 without containing either end
+	inBounds := idx.EdgesInBounds(-0.0006, 0.0019, -0.0004, 0.0021)
+	if len(inBounds) != 2 || inBounds[0].WayID != 11 || inBounds[1].WayID != 11 {
+		t.Errorf("expected both edges of way 11 in bounds, got %v", inBounds)
+	}
+}
+
+func TestIndexNearestMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := New(osm.Drive)
+
+	for i := int64(1); i <= 500; i++ {
+		g.AddNode(&Node{ID: i, Lat: -37.8 + r.Float64()*0.1, Lon: 144.9 + r.Float64()*0.1})
+	}
+
+	idx := NewIndex(g)
+
+	for i := 0; i < 50; i++ {
+		lat, lon := -37.8+r.Float64()*0.1, 144.9+r.Float64()*0.1
+
+		var brute *Node
+		best := math.Inf(1)
+		for _, n := range g.Nodes {
+			if d := helpers.Haversine(lat, lon, n.Lat, n.Lon); d < best {
+				brute, best = n, d
+			}
+		}
+
+		if n := idx.NearestNode(lat, lon); n != brute {
+			t.Errorf("(%v, %v): expected nearest node %d, got %d", lat, lon, brute.ID, n.ID)
+		}
+
+		within := idx.NodesWithin(lat, lon, 1000)
+		count := 0
+		for _, n := range g.Nodes {
+			if helpers.Haversine(lat, lon, n.Lat, n.Lon) <= 1000 {
+				count++
+			}
+		}
+
+		// Planar distances may differ from haversine at the very edge of the radius
+		if math.Abs(float64(len(within)-count)) > 1 {
+			t.Errorf("(%v, %v): expected about %d nodes within 1000 metres, got %d", lat, lon, count, len(within))
+		}
+	}
+}
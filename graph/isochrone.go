@@ -0,0 +1,194 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/geojson"
+	"github.com/captchanjack/osmdata/helpers"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+// Isochrone is the part of a network reachable from the sources within a threshold
+type Isochrone struct {
+	Threshold float64           // Cost limit in units of the weight, e.g. seconds for TravelTime
+	Graph     *Graph            // Nodes and edges reachable in full within the threshold
+	Polygon   *geojson.Geometry // Hull of the reachable network, nil if it has no area
+}
+
+// IsochroneOptions configures an isochrone computation, the zero value is valid.
+//
+// The polygon is always a convex hull. Concave hulls are left out on purpose: they depend on a
+// length parameter that has no good default across networks and thresholds, too small a value
+// cuts reachable edges out of the polygon or splits it, and the result can self-intersect. For a
+// tighter shape, export the subgraph of the isochrone and buffer its edges instead.
+type IsochroneOptions struct {
+	Weight Weight  // Cost of an edge, defaults to TravelTime
+	Buffer float64 // Metres the convex hull of the reachable network is grown by
+}
+
+// Returns an isochrone per threshold reachable from any of the source nodes, in the order of the
+// thresholds. Edges only partly reachable within a threshold contribute the reachable part of
+// their geometry to the polygon but are left out of the subgraph.
+func (g *Graph) Isochrones(sources []int64, thresholds []float64, options *IsochroneOptions) ([]*Isochrone, error) {
+	if options == nil {
+		options = &IsochroneOptions{}
+	}
+
+	weight := options.Weight
+	if weight == nil {
+		weight = TravelTime
+	}
+
+	limit := 0.0
+	for _, t := range thresholds {
+		limit = math.Max(limit, t)
+	}
+
+	costs := map[int64]float64{}
+	startCosts := map[int]float64{}
+	edgeCosts := map[int]float64{}
+
+	g.search(sources, weight, func(n *Node) float64 { return 0 }, limit, func(l *searchLabel) bool {
+		if _, ok := costs[l.node]; !ok {
+			costs[l.node] = l.cost
+		}
+		// Cost of reaching the end of the edge, labels are visited in order of cost
+		if l.edge != nil {
+			if _, ok := edgeCosts[l.edge.ID]; !ok {
+				edgeCosts[l.edge.ID] = l.cost
+			}
+		}
+		// Cost of starting along each edge the label may turn onto, so edges only reachable by a
+		// restricted turn are not drawn
+		for _, e := range g.OutEdges(l.node) {
+			if _, ok := startCosts[e.ID]; ok {
+				continue
+			}
+			if _, ok := g.Turn(l.turn, l.edge, e); ok {
+				startCosts[e.ID] = l.cost
+			}
+		}
+		return false
+	})
+
+	if len(costs) == 0 {
+		return nil, fmt.Errorf("none of the source nodes %v are in the graph", sources)
+	}
+
+	isochrones := make([]*Isochrone, len(thresholds))
+
+	for i, threshold := range thresholds {
+		var ids []int64
+		var points [][]float64
+
+		for id, cost := range costs {
+			if cost <= threshold {
+				ids = append(ids, id)
+				points = append(points, []float64{g.Nodes[id].Lon, g.Nodes[id].Lat})
+			}
+		}
+
+		for _, e := range g.Edges {
+			cost, ok := startCosts[e.ID]
+			if !ok || cost > threshold {
+				continue
+			}
+
+			w := weight(e)
+			if math.IsInf(w, 1) || math.IsNaN(w) {
+				continue
+			}
+
+			reach := e.Length
+			if w > threshold-cost {
+				reach = e.Length * (threshold - cost) / w
+			}
+
+			points = append(points, lineStart(e.Geometry, reach)...)
+		}
+
+		reachable := func(e *Edge) bool {
+			cost, ok := edgeCosts[e.ID]
+			return ok && cost <= threshold
+		}
+
+		isochrones[i] = &Isochrone{Threshold: threshold, Graph: g.subgraph(ids, reachable)}
+
+		if ring := helpers.BufferedHull(points, options.Buffer); ring != nil {
+			isochrones[i].Polygon = geojson.NewPolygon([][][]float64{ring})
+		}
+	}
+
+	return isochrones, nil
+}
+
+// Downloads the network within radius metres of a point with GetPresetQueryByRadius, and returns
+// the isochrones from the node nearest to the point. The radius must cover the largest threshold,
+// e.g. a 10 minute walk needs about 850 metres.
+func IsochronesAround(
+	lat float64,
+	lon float64,
+	radius float64,
+	mode osm.PresetNetworkType,
+	thresholds []float64,
+	options *IsochroneOptions,
+) ([]*Isochrone, error) {
+	return IsochronesAroundContext(context.Background(), ovp.DefaultClient, lat, lon, radius, mode, thresholds, options)
+}
+
+// IsochronesAround with a context and the Overpass client to download with, cancelling ctx aborts
+// the download
+func IsochronesAroundContext(
+	ctx context.Context,
+	client *ovp.Client,
+	lat float64,
+	lon float64,
+	radius float64,
+	mode osm.PresetNetworkType,
+	thresholds []float64,
+	options *IsochroneOptions,
+) ([]*Isochrone, error) {
+	query := osm.GetPresetQueryByRadius(mode, false, ovp.JSON, radius, lat, lon)
+
+	resp, err := query.WithClient(client).ExecuteResponseContext(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("encountered error during network download: %w", err)
+	}
+
+	g, err := Build(resp, mode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	source := NewIndex(g).NearestNode(lat, lon)
+
+	if source == nil {
+		return nil, fmt.Errorf("no network found within %v metres of (%v, %v)", radius, lat, lon)
+	}
+
+	return g.Isochrones([]int64{source.ID}, thresholds, options)
+}
+
+// Returns the coordinates of a line up to the given distance in metres along it
+func lineStart(coordinates [][]float64, distance float64) [][]float64 {
+	start := [][]float64{coordinates[0]}
+
+	for i := 1; i < len(coordinates); i++ {
+		a, b := coordinates[i-1], coordinates[i]
+		length := helpers.Haversine(a[1], a[0], b[1], b[0])
+
+		if length >= distance {
+			return append(start, helpers.InterpolateLine([][]float64{a, b}, distance))
+		}
+
+		distance -= length
+		start = append(start, b)
+	}
+
+	return start
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+func TestIsochrones(t *testing.T) {
+	g := buildTestGraph(t, osm.Walk, testNetwork)
+
+	var testCases = []struct {
+		threshold float64
+		buffer    float64
+		nodes     int
+		edges     int
+		polygon   bool
+	}{
+		// Only node 1, the reachable part of way 10 is a line with no area
+		{150, 0, 1, 0, false},
+		{150, 50, 1, 0, true},
+		// Node 3 is reachable but going back along way 10 to node 1 is not
+		{250, 0, 2, 1, true},
+		{400, 0, 3, 2, true},
+		{1000, 0, 4, 6, true},
+	}
+
+	for _, test := range testCases {
+		isochrones, err := g.Isochrones([]int64{1}, []float64{test.threshold}, &IsochroneOptions{Weight: Distance, Buffer: test.buffer})
+
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.threshold, err)
+		}
+
+		iso := isochrones[0]
+
+		if len(iso.Graph.Nodes) != test.nodes || len(iso.Graph.Edges) != test.edges {
+			t.Errorf("%v: expected %d nodes and %d edges, got %d and %d", test.threshold, test.nodes, test.edges, len(iso.Graph.Nodes), len(iso.Graph.Edges))
+		}
+
+		if (iso.Polygon != nil) != test.polygon {
+			t.Errorf("%v: expected polygon %v, got %v", test.threshold, test.polygon, iso.Polygon)
+		}
+
+		if iso.Polygon == nil {
+			continue
+		}
+
+		ring := iso.Polygon.Coordinates.([][][]float64)[0]
+
+		for _, id := range iso.Graph.NodeIDs() {
+			n := iso.Graph.Nodes[id]
+			if test.buffer > 0 && !helpers.PointInRing([]float64{n.Lon, n.Lat}, ring) {
+				t.Errorf("%v: node %d outside the buffered polygon", test.threshold, id)
+			}
+		}
+	}
+
+	if _, err := g.Isochrones([]int64{99}, []float64{100}, nil); err == nil {
+		t.Errorf("expected error for a source outside the graph")
+	}
+
+	// Way 41 cannot be entered from way 40, so no part of it is reachable from node 1
+	g = buildTestGraph(t, osm.Drive, testChainNetwork)
+	g.AddRestriction(&Restriction{Type: "no_straight_on", FromWay: 40, ViaNode: 2, ToWay: 41})
+
+	isochrones, err := g.Isochrones([]int64{1}, []float64{1000}, &IsochroneOptions{Weight: Distance, Buffer: 10})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ring := isochrones[0].Polygon.Coordinates.([][][]float64)[0]
+
+	if helpers.PointInRing([]float64{0.0015, 0}, ring) {
+		t.Errorf("expected the restricted way 41 to be left out of the polygon %v", ring)
+	}
+
+	if !helpers.PointInRing([]float64{0.0005, 0}, ring) {
+		t.Errorf("expected way 40 to be within the polygon %v", ring)
+	}
+}
@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"strings"
+
+	osm "github.com/captchanjack/osmdata"
+	ovp "github.com/captchanjack/osmdata/overpass"
+)
+
+// Restriction is a turn restriction relation, travelling from FromWay through ViaNode (or along
+// ViaWays) onto ToWay is forbidden for no_* restrictions, and is the only allowed continuation for
+// only_* restrictions.
+type Restriction struct {
+	ID      int64    // OSM relation id
+	Type    string   // Value of the restriction tag, e.g. no_left_turn or only_straight_on
+	Only    bool     // True for only_* restrictions
+	FromWay int64    // Way the turn starts on
+	ViaNode int64    // Node the turn happens at, zero when ViaWays is set
+	ViaWays []int64  // Ways travelled between FromWay and ToWay, in order
+	ToWay   int64    // Way the turn ends on
+	Except  []string // Vehicle types the restriction does not apply to
+}
+
+// Vehicle types a restriction tag can be qualified with for each network type, in order of
+// precedence, e.g. restriction:motorcar overrides restriction for Drive
+var restrictionVehicles = map[osm.PresetNetworkType][]string{
+	osm.Drive:          {"motorcar", "motor_vehicle", "vehicle"},
+	osm.DriveMainroads: {"motorcar", "motor_vehicle", "vehicle"},
+	osm.DriveService:   {"motorcar", "motor_vehicle", "vehicle"},
+	osm.All:            {"motorcar", "motor_vehicle", "vehicle"},
+	osm.AllPrivate:     {"motorcar", "motor_vehicle", "vehicle"},
+	osm.None:           {"motorcar", "motor_vehicle", "vehicle"},
+	osm.Bike:           {"bicycle", "vehicle"},
+}
+
+// Adds a restriction, searches started after this respect it
+func (g *Graph) AddRestriction(r *Restriction) {
+	g.Restrictions = append(g.Restrictions, r)
+
+	if g.restrictionsFrom == nil {
+		g.restrictionsFrom = map[int64][]*Restriction{}
+	}
+	g.restrictionsFrom[r.FromWay] = append(g.restrictionsFrom[r.FromWay], r)
+}
+
+// Parses a type=restriction relation for the network type, ok is false if the relation is
+// malformed, conditional, not applicable to the network type or exempts it with except=*
+func ParseRestriction(rel *ovp.RelationElement, mode osm.PresetNetworkType) (r *Restriction, ok bool) {
+	if rel.Tags["type"] != "restriction" {
+		return nil, false
+	}
+
+	vehicles, applies := restrictionVehicles[mode]
+	if !applies {
+		return nil, false
+	}
+
+	r = &Restriction{ID: rel.ID}
+
+	for _, v := range vehicles {
+		if value, ok := rel.Tags["restriction:"+v]; ok {
+			r.Type = value
+			break
+		}
+	}
+
+	if r.Type == "" {
+		r.Type = rel.Tags["restriction"]
+	}
+
+	switch {
+	case strings.HasPrefix(r.Type, "no_"):
+	case strings.HasPrefix(r.Type, "only_"):
+		r.Only = true
+	default:
+		return nil, false
+	}
+
+	if except := rel.Tags["except"]; except != "" {
+		for _, e := range strings.Split(except, ";") {
+			e = strings.TrimSpace(e)
+			r.Except = append(r.Except, e)
+
+			// Exempt when the most specific vehicle type of the network is listed
+			if e == vehicles[0] {
+				return nil, false
+			}
+		}
+	}
+
+	from, to := 0, 0
+
+	for _, m := range rel.Members {
+		switch {
+		case m.Role == "from" && m.Type == ovp.Way:
+			r.FromWay = m.Ref
+			from++
+		case m.Role == "to" && m.Type == ovp.Way:
+			r.ToWay = m.Ref
+			to++
+		case m.Role == "via" && m.Type == ovp.Node:
+			r.ViaNode = m.Ref
+		case m.Role == "via" && m.Type == ovp.Way:
+			r.ViaWays = append(r.ViaWays, m.Ref)
+		}
+	}
+
+	// Exactly one from and to way, and either a via node or via ways
+	if from != 1 || to != 1 || (r.ViaNode == 0) == (len(r.ViaWays) == 0) {
+		return nil, false
+	}
+
+	return r, true
+}
+
+// TurnState tracks progress along a via way restriction between consecutive edges of a path, the
+// zero value is the state at the start of a path
+type TurnState struct {
+	active   *Restriction // Via way restriction whose from way has been left onto its via ways
+	progress int          // Index of the via way currently travelled
+}
+
+// Reports whether a path arriving on edge from may continue onto edge to, and the state to pass
+// along with edge to for the next turn. A nil from edge (the start of a path) allows every edge.
+//
+// Via node restrictions are checked on every turn. Only one via way restriction is tracked at a
+// time, the first one entered: a via way restriction starting on the via ways of the tracked one is
+// not applied until the tracked one has been left or completed.
+func (g *Graph) Turn(state TurnState, from *Edge, to *Edge) (TurnState, bool) {
+	if from == nil {
+		return TurnState{}, true
+	}
+
+	for _, r := range g.restrictionsFrom[from.WayID] {
+		if r.ViaNode != 0 && r.ViaNode == from.To && r.Only != entersToWay(r, from, to) {
+			return TurnState{}, false
+		}
+	}
+
+	if r := state.active; r != nil {
+		switch {
+		case to.WayID == r.ViaWays[state.progress]:
+			return state, true
+		case state.progress+1 < len(r.ViaWays) && to.WayID == r.ViaWays[state.progress+1]:
+			return TurnState{r, state.progress + 1}, true
+		case state.progress == len(r.ViaWays)-1 && to.WayID == r.ToWay:
+			return TurnState{}, r.Only
+		default:
+			// Leaving the via ways is fine unless they lead only to the to way
+			if r.Only {
+				return TurnState{}, false
+			}
+		}
+	}
+
+	for _, r := range g.restrictionsFrom[from.WayID] {
+		if r.ViaNode == 0 && to.WayID == r.ViaWays[0] && to.WayID != from.WayID {
+			return TurnState{r, 0}, true
+		}
+	}
+
+	return TurnState{}, true
+}
+
+// Reports whether the turn from edge from onto edge to enters the to way of a via node restriction.
+// When the from and to ways are the same, as for no_u_turn, only turning back along the way does.
+func entersToWay(r *Restriction, from *Edge, to *Edge) bool {
+	if to.WayID != r.ToWay {
+		return false
+	}
+
+	if r.FromWay != r.ToWay {
+		return true
+	}
+
+	return to.To == from.From || to.Reversed != from.Reversed
+}
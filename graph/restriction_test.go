@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+)
+
+// A junction at node 3 and another at node 5, joined by way 22:
+//
+//	1 --- 3 --- 4   (ways 20 and 21)
+//	      |
+//	      5 --- 6   (way 22 from 3 to 5, way 23 from 5 to 6)
+//	      |
+//	      8         (way 25)
+//
+// Relation 100 forbids going straight on from 20 to 21 at node 3, relation 101 only allows going
+// straight on from 21 to 20 except for bicycles, relation 102 forbids turning from 20 along 22 onto
+// 23 and relation 103 is malformed.
+const testRestrictionNetwork = `{
+  "elements": [
+    {"type": "node", "id": 1, "lat": 0, "lon": 0.000},
+    {"type": "node", "id": 3, "lat": 0, "lon": 0.001},
+    {"type": "node", "id": 4, "lat": 0, "lon": 0.002},
+    {"type": "node", "id": 5, "lat": -0.001, "lon": 0.001},
+    {"type": "node", "id": 6, "lat": -0.001, "lon": 0.002},
+    {"type": "node", "id": 8, "lat": -0.002, "lon": 0.001},
+    {"type": "way", "id": 20, "nodes": [1, 3], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 21, "nodes": [3, 4], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 22, "nodes": [3, 5], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 23, "nodes": [5, 6], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 25, "nodes": [5, 8], "tags": {"highway": "residential"}},
+    {"type": "relation", "id": 100, "members": [
+      {"type": "way", "ref": 20, "role": "from"},
+      {"type": "node", "ref": 3, "role": "via"},
+      {"type": "way", "ref": 21, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "no_straight_on"}},
+    {"type": "relation", "id": 101, "members": [
+      {"type": "way", "ref": 21, "role": "from"},
+      {"type": "node", "ref": 3, "role": "via"},
+      {"type": "way", "ref": 20, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "only_straight_on", "except": "psv;bicycle"}},
+    {"type": "relation", "id": 102, "members": [
+      {"type": "way", "ref": 20, "role": "from"},
+      {"type": "way", "ref": 22, "role": "via"},
+      {"type": "way", "ref": 23, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction:motorcar": "no_left_turn"}},
+    {"type": "relation", "id": 103, "members": [
+      {"type": "way", "ref": 20, "role": "from"},
+      {"type": "way", "ref": 21, "role": "from"},
+      {"type": "node", "ref": 3, "role": "via"},
+      {"type": "way", "ref": 22, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "no_right_turn"}}
+  ]
+}`
+
+// Follows the path through the node ids, reporting whether every turn along it is allowed
+func pathAllowed(t *testing.T, g *Graph, nodes ...int64) bool {
+	var state TurnState
+	var prev *Edge
+
+	for i := 1; i < len(nodes); i++ {
+		var edge *Edge
+
+		for _, e := range g.OutEdges(nodes[i-1]) {
+			if e.To == nodes[i] {
+				edge = e
+			}
+		}
+
+		if edge == nil {
+			t.Fatalf("no edge from %d to %d", nodes[i-1], nodes[i])
+		}
+
+		var ok bool
+
+		if state, ok = g.Turn(state, prev, edge); !ok {
+			return false
+		}
+
+		prev = edge
+	}
+
+	return true
+}
+
+func TestParseRestriction(t *testing.T) {
+	var testCases = []struct {
+		mode         osm.PresetNetworkType
+		restrictions int
+	}{
+		{osm.Drive, 3},
+		{osm.Bike, 1},
+		{osm.Walk, 0},
+	}
+
+	for _, test := range testCases {
+		g := buildTestGraph(t, test.mode, testRestrictionNetwork)
+
+		if len(g.Restrictions) != test.restrictions {
+			t.Errorf("%v: expected %d restrictions, got %d", test.mode, test.restrictions, len(g.Restrictions))
+		}
+	}
+
+	g := buildTestGraph(t, osm.Drive, testRestrictionNetwork)
+	r := g.Restrictions[2]
+
+	if r.ID != 102 || r.Type != "no_left_turn" || r.Only || r.FromWay != 20 || r.ViaNode != 0 || len(r.ViaWays) != 1 || r.ToWay != 23 {
+		t.Errorf("unexpected via way restriction: %+v", r)
+	}
+}
+
+func TestTurn(t *testing.T) {
+	var testCases = []struct {
+		mode    osm.PresetNetworkType
+		path    []int64
+		allowed bool
+	}{
+		{osm.Drive, []int64{1, 3, 4}, false},
+		{osm.Drive, []int64{1, 3, 5}, true},
+		{osm.Drive, []int64{4, 3, 1}, true},
+		{osm.Drive, []int64{4, 3, 5}, false},
+		{osm.Drive, []int64{1, 3, 5, 6}, false},
+		{osm.Drive, []int64{1, 3, 5, 8}, true},
+		{osm.Drive, []int64{4, 3, 5, 6}, false},
+		{osm.Drive, []int64{8, 5, 3, 4}, true},
+		{osm.Bike, []int64{1, 3, 4}, false},
+		{osm.Bike, []int64{4, 3, 5}, true},
+		{osm.Bike, []int64{1, 3, 5, 6}, true},
+		{osm.Walk, []int64{1, 3, 4}, true},
+	}
+
+	for _, test := range testCases {
+		g := buildTestGraph(t, test.mode, testRestrictionNetwork)
+
+		if allowed := pathAllowed(t, g, test.path...); allowed != test.allowed {
+			t.Errorf("%v %v: expected allowed %v, got %v", test.mode, test.path, test.allowed, allowed)
+		}
+	}
+}
+
+// A way 30 through node 2 with a side way 31, and a chain of ways 40, 41, 45, 46 with branches 43
+// and 47:
+//
+//	1 --- 2 --- 3   (way 30)        10 --- 11 --- 12 --- 13 --- 14   (ways 40, 41, 45, 46)
+//	      |                                       |      |
+//	      4         (way 31)                      15     16          (ways 43 and 47)
+//
+// Relation 300 forbids u-turns on way 30 at node 2. Relation 400 forbids going from 40 along 41
+// and 45 onto 46, relation 401 forbids bicycles going straight on from 41 onto 45 at node 12 and
+// relation 402 forbids going from 41 along 45 onto 47.
+const testOverlappingRestrictions = `{
+  "elements": [
+    {"type": "node", "id": 1, "lat": 0, "lon": 0.000},
+    {"type": "node", "id": 2, "lat": 0, "lon": 0.001},
+    {"type": "node", "id": 3, "lat": 0, "lon": 0.002},
+    {"type": "node", "id": 4, "lat": -0.001, "lon": 0.001},
+    {"type": "node", "id": 10, "lat": 0.01, "lon": 0.000},
+    {"type": "node", "id": 11, "lat": 0.01, "lon": 0.001},
+    {"type": "node", "id": 12, "lat": 0.01, "lon": 0.002},
+    {"type": "node", "id": 13, "lat": 0.01, "lon": 0.003},
+    {"type": "node", "id": 14, "lat": 0.01, "lon": 0.004},
+    {"type": "node", "id": 15, "lat": 0.009, "lon": 0.002},
+    {"type": "node", "id": 16, "lat": 0.009, "lon": 0.003},
+    {"type": "way", "id": 30, "nodes": [1, 2, 3], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 31, "nodes": [2, 4], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 40, "nodes": [10, 11], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 41, "nodes": [11, 12], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 45, "nodes": [12, 13], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 46, "nodes": [13, 14], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 43, "nodes": [12, 15], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 47, "nodes": [13, 16], "tags": {"highway": "residential"}},
+    {"type": "relation", "id": 300, "members": [
+      {"type": "way", "ref": 30, "role": "from"},
+      {"type": "node", "ref": 2, "role": "via"},
+      {"type": "way", "ref": 30, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "no_u_turn"}},
+    {"type": "relation", "id": 400, "members": [
+      {"type": "way", "ref": 40, "role": "from"},
+      {"type": "way", "ref": 41, "role": "via"},
+      {"type": "way", "ref": 45, "role": "via"},
+      {"type": "way", "ref": 46, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "no_straight_on"}},
+    {"type": "relation", "id": 401, "members": [
+      {"type": "way", "ref": 41, "role": "from"},
+      {"type": "node", "ref": 12, "role": "via"},
+      {"type": "way", "ref": 45, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction:bicycle": "no_straight_on"}},
+    {"type": "relation", "id": 402, "members": [
+      {"type": "way", "ref": 41, "role": "from"},
+      {"type": "way", "ref": 45, "role": "via"},
+      {"type": "way", "ref": 47, "role": "to"}
+    ], "tags": {"type": "restriction", "restriction": "no_right_turn"}}
+  ]
+}`
+
+func TestTurnOverlapping(t *testing.T) {
+	var testCases = []struct {
+		mode    osm.PresetNetworkType
+		path    []int64
+		allowed bool
+	}{
+		// A u-turn restriction on a single way only forbids turning back
+		{osm.Drive, []int64{1, 2, 3}, true},
+		{osm.Drive, []int64{3, 2, 1}, true},
+		{osm.Drive, []int64{1, 2, 1}, false},
+		{osm.Drive, []int64{3, 2, 3}, false},
+		{osm.Drive, []int64{1, 2, 4}, true},
+		// Via node restrictions apply while a via way restriction is tracked
+		{osm.Bike, []int64{11, 12, 13}, false},
+		{osm.Bike, []int64{10, 11, 12, 13}, false},
+		{osm.Bike, []int64{10, 11, 12, 15}, true},
+		{osm.Drive, []int64{10, 11, 12, 13, 14}, false},
+		{osm.Drive, []int64{11, 12, 13, 16}, false},
+		// Relation 402 starts on the via ways of relation 400, it is not applied while 400 is
+		// tracked, a known limitation of TurnState
+		{osm.Drive, []int64{10, 11, 12, 13, 16}, true},
+	}
+
+	for _, test := range testCases {
+		g := buildTestGraph(t, test.mode, testOverlappingRestrictions)
+
+		if allowed := pathAllowed(t, g, test.path...); allowed != test.allowed {
+			t.Errorf("%v %v: expected allowed %v, got %v", test.mode, test.path, test.allowed, allowed)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package graph
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/captchanjack/osmdata/geojson"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// Returned (wrapped) when the target can't be reached from the source
+var ErrNoPath = errors.New("no path between the nodes")
+
+// Weight is the cost of travelling an edge, +Inf marks the edge as impassable
+type Weight func(e *Edge) float64
+
+// Heuristic estimates the cost from a node to the target of an A* search, it must never
+// overestimate the cost for the search to return shortest paths
+type Heuristic func(n *Node, target *Node) float64
+
+// Weights edges by length in metres
+func Distance(e *Edge) float64 {
+	return e.Length
+}
+
+// Weights edges by travel time in seconds at the edge speed
+func TravelTime(e *Edge) float64 {
+	if e.Speed <= 0 {
+		return math.Inf(1)
+	}
+	return e.Length / (e.Speed / 3.6)
+}
+
+// Estimates the Distance weight by the great-circle distance
+func DistanceHeuristic(n *Node, target *Node) float64 {
+	return helpers.Haversine(n.Lat, n.Lon, target.Lat, target.Lon)
+}
+
+// Returns a heuristic estimating the TravelTime weight by the great-circle distance travelled at the
+// highest edge speed in the graph
+func (g *Graph) TravelTimeHeuristic() Heuristic {
+	maxSpeed := 0.0
+	for _, e := range g.Edges {
+		maxSpeed = math.Max(maxSpeed, e.Speed)
+	}
+
+	return func(n *Node, target *Node) float64 {
+		if maxSpeed == 0 {
+			return 0
+		}
+		return DistanceHeuristic(n, target) / (maxSpeed / 3.6)
+	}
+}
+
+// Path is a route through the graph
+type Path struct {
+	Nodes  []int64 // Ids of the nodes visited, from source to target
+	Edges  []*Edge // Edges travelled, one fewer than Nodes
+	Weight float64 // Total weight of the edges
+	Length float64 // Total length in metres
+}
+
+// Returns the [lon, lat] coordinates of the path, following the edge geometry
+func (p *Path) Coordinates(g *Graph) [][]float64 {
+	if len(p.Edges) == 0 {
+		coordinates := [][]float64{}
+		for _, id := range p.Nodes {
+			if n, ok := g.Nodes[id]; ok {
+				coordinates = append(coordinates, []float64{n.Lon, n.Lat})
+			}
+		}
+		return coordinates
+	}
+
+	coordinates := [][]float64{p.Edges[0].Geometry[0]}
+	for _, e := range p.Edges {
+		coordinates = append(coordinates, e.Geometry[1:]...)
+	}
+	return coordinates
+}
+
+// Returns the path as a GeoJSON LineString
+func (p *Path) LineString(g *Graph) *geojson.Geometry {
+	return geojson.NewLineString(p.Coordinates(g))
+}
+
+// Returns the shortest path between two nodes by Dijkstra's algorithm, respecting turn restrictions
+func (g *Graph) ShortestPath(source int64, target int64, weight Weight) (*Path, error) {
+	return g.AStar(source, target, weight, nil)
+}
+
+// Returns the shortest path between two nodes by A* search guided by the heuristic, respecting
+// turn restrictions. A nil heuristic searches like Dijkstra's algorithm.
+func (g *Graph) AStar(source int64, target int64, weight Weight, heuristic Heuristic) (*Path, error) {
+	if _, ok := g.Nodes[source]; !ok {
+		return nil, fmt.Errorf("source node %d is not in the graph", source)
+	}
+
+	t, ok := g.Nodes[target]
+	if !ok {
+		return nil, fmt.Errorf("target node %d is not in the graph", target)
+	}
+
+	estimate := func(n *Node) float64 { return 0 }
+	if heuristic != nil {
+		estimate = func(n *Node) float64 { return heuristic(n, t) }
+	}
+
+	var found *searchLabel
+
+	g.search([]int64{source}, weight, estimate, math.Inf(1), func(l *searchLabel) bool {
+		if l.node == target {
+			found = l
+			return true
+		}
+		return false
+	})
+
+	if found == nil {
+		return nil, fmt.Errorf("encountered error during routing from %d to %d: %w", source, target, ErrNoPath)
+	}
+
+	return found.path(), nil
+}
+
+// searchLabel is a state of the edge based search, the node reached by an edge along with the
+// progress through turn restrictions
+type searchLabel struct {
+	node     int64
+	edge     *Edge // Edge the node was reached by, nil at a source
+	turn     TurnState
+	cost     float64
+	priority float64
+	prev     *searchLabel
+}
+
+type searchKey struct {
+	node int64
+	edge int
+	turn TurnState
+}
+
+func (l *searchLabel) key() searchKey {
+	edge := -1
+	if l.edge != nil {
+		edge = l.edge.ID
+	}
+	return searchKey{l.node, edge, l.turn}
+}
+
+func (l *searchLabel) path() *Path {
+	p := &Path{Weight: l.cost}
+
+	for ; l != nil; l = l.prev {
+		p.Nodes = append(p.Nodes, l.node)
+		if l.edge != nil {
+			p.Edges = append(p.Edges, l.edge)
+			p.Length += l.edge.Length
+		}
+	}
+
+	for i, j := 0, len(p.Nodes)-1; i < j; i, j = i+1, j-1 {
+		p.Nodes[i], p.Nodes[j] = p.Nodes[j], p.Nodes[i]
+	}
+
+	for i, j := 0, len(p.Edges)-1; i < j; i, j = i+1, j-1 {
+		p.Edges[i], p.Edges[j] = p.Edges[j], p.Edges[i]
+	}
+
+	return p
+}
+
+type searchQueue []*searchLabel
+
+func (q searchQueue) Len() int           { return len(q) }
+func (q searchQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q searchQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *searchQueue) Push(x interface{}) {
+	*q = append(*q, x.(*searchLabel))
+}
+
+func (q *searchQueue) Pop() interface{} {
+	old := *q
+	l := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return l
+}
+
+// Runs an edge based best-first search from the sources, calling visit with every settled label in
+// order of cost until it returns true. Labels costing more than limit are not expanded.
+func (g *Graph) search(sources []int64, weight Weight, estimate func(n *Node) float64, limit float64, visit func(l *searchLabel) bool) {
+	best := map[searchKey]*searchLabel{}
+	settled := map[searchKey]bool{}
+	queue := &searchQueue{}
+
+	for _, id := range sources {
+		n, ok := g.Nodes[id]
+		if !ok {
+			continue
+		}
+
+		l := &searchLabel{node: id, priority: estimate(n)}
+		if _, ok := best[l.key()]; !ok {
+			best[l.key()] = l
+			heap.Push(queue, l)
+		}
+	}
+
+	for queue.Len() > 0 {
+		l := heap.Pop(queue).(*searchLabel)
+		key := l.key()
+
+		if settled[key] {
+			continue
+		}
+		settled[key] = true
+
+		if visit(l) {
+			return
+		}
+
+		for _, e := range g.OutEdges(l.node) {
+			turn, ok := g.Turn(l.turn, l.edge, e)
+			if !ok {
+				continue
+			}
+
+			w := weight(e)
+			if math.IsInf(w, 1) || math.IsNaN(w) {
+				continue
+			}
+
+			cost := l.cost + w
+			if cost > limit {
+				continue
+			}
+
+			next := &searchLabel{node: e.To, edge: e, turn: turn, cost: cost, prev: l}
+			nextKey := next.key()
+
+			if settled[nextKey] {
+				continue
+			}
+
+			if b, ok := best[nextKey]; ok && b.cost <= cost {
+				continue
+			}
+
+			next.priority = cost + estimate(g.Nodes[e.To])
+			best[nextKey] = next
+			heap.Push(queue, next)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/geojson"
+)
+
+func TestShortestPath(t *testing.T) {
+	var testCases = []struct {
+		mode   osm.PresetNetworkType
+		source int64
+		target int64
+		nodes  []int64
+	}{
+		{osm.Walk, 1, 4, []int64{1, 3, 4}},
+		// Going straight on from way 20 to 21 is forbidden, turning around at node 5 is not
+		{osm.Drive, 1, 4, []int64{1, 3, 5, 3, 4}},
+		{osm.Drive, 4, 1, []int64{4, 3, 1}},
+		{osm.Drive, 1, 6, []int64{1, 3, 5, 8, 5, 6}},
+		{osm.Drive, 3, 3, []int64{3}},
+	}
+
+	for _, test := range testCases {
+		g := buildTestGraph(t, test.mode, testRestrictionNetwork)
+
+		dijkstra, err := g.ShortestPath(test.source, test.target, Distance)
+		if err != nil {
+			t.Fatalf("%v %d-%d: unexpected error: %v", test.mode, test.source, test.target, err)
+		}
+
+		astar, err := g.AStar(test.source, test.target, Distance, DistanceHeuristic)
+		if err != nil {
+			t.Fatalf("%v %d-%d: unexpected error: %v", test.mode, test.source, test.target, err)
+		}
+
+		for _, p := range []*Path{dijkstra, astar} {
+			if !reflect.DeepEqual(p.Nodes, test.nodes) {
+				t.Errorf("%v %d-%d: expected nodes %v, got %v", test.mode, test.source, test.target, test.nodes, p.Nodes)
+			}
+
+			if len(p.Edges) != len(p.Nodes)-1 {
+				t.Errorf("%v %d-%d: expected %d edges, got %d", test.mode, test.source, test.target, len(p.Nodes)-1, len(p.Edges))
+			}
+
+			if math.Abs(p.Weight-p.Length) > 1e-6 {
+				t.Errorf("%v %d-%d: distance weight %v should equal length %v", test.mode, test.source, test.target, p.Weight, p.Length)
+			}
+		}
+	}
+}
+
+func TestShortestPathErrors(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testNetwork)
+
+	// Way 10 is oneway from 1 to 4
+	if _, err := g.ShortestPath(4, 1, Distance); !errors.Is(err, ErrNoPath) {
+		t.Errorf("expected ErrNoPath, got %v", err)
+	}
+
+	if _, err := g.ShortestPath(1, 99, Distance); err == nil || errors.Is(err, ErrNoPath) {
+		t.Errorf("expected missing node error, got %v", err)
+	}
+}
+
+func TestTravelTime(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testNetwork)
+
+	p, err := g.AStar(1, 6, TravelTime, g.TravelTimeHeuristic())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Primary at 60 km/h to node 3 then residential at 30 km/h
+	expected := p.Edges[0].Length/(60/3.6) + p.Edges[1].Length/(30/3.6)
+
+	if math.Abs(p.Weight-expected) > 1e-6 {
+		t.Errorf("expected travel time %v, got %v", expected, p.Weight)
+	}
+
+	line := p.LineString(g)
+	coordinates := line.Coordinates.([][]float64)
+
+	if line.Type != geojson.LineString || len(coordinates) != 5 {
+		t.Errorf("expected LineString with 5 coordinates, got %v with %d", line.Type, len(coordinates))
+	}
+}
+
+func TestParseMaxspeed(t *testing.T) {
+	var testCases = []struct {
+		value string
+		speed float64
+		ok    bool
+	}{
+		{"50", 50, true},
+		{"30 mph", 48.28032, true},
+		{"10 knots", 18.52, true},
+		{"DE:urban", 50, true},
+		{"walk", 6, true},
+		{"60;40", 40, true},
+		{"none", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range testCases {
+		speed, ok := ParseMaxspeed(test.value)
+
+		if ok != test.ok || math.Abs(speed-test.speed) > 1e-6 {
+			t.Errorf("%v: expected %v %v, got %v %v", test.value, test.speed, test.ok, speed, ok)
+		}
+	}
+}
+
+func TestSpeed(t *testing.T) {
+	var testCases = []struct {
+		tags     map[string]string
+		mode     osm.PresetNetworkType
+		reversed bool
+		speed    float64
+	}{
+		{map[string]string{"highway": "primary"}, osm.Drive, false, 60},
+		{map[string]string{"highway": "primary", "maxspeed": "70"}, osm.Drive, false, 70},
+		{map[string]string{"highway": "primary", "maxspeed": "70", "maxspeed:backward": "50"}, osm.Drive, true, 50},
+		{map[string]string{"highway": "pedestrian"}, osm.Drive, false, FallbackSpeed},
+		{map[string]string{"highway": "primary", "maxspeed": "70"}, osm.Walk, false, WalkSpeed},
+		{map[string]string{"highway": "living_street", "maxspeed": "10"}, osm.Bike, false, 10},
+		{map[string]string{"railway": "tram"}, osm.Rail, false, 30},
+	}
+
+	for _, test := range testCases {
+		if speed := Speed(test.tags, test.mode, test.reversed); speed != test.speed {
+			t.Errorf("%v %v: expected %v, got %v", test.tags, test.mode, test.speed, speed)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package graph
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// Removes the interstitial nodes of the graph, merging the edges through every node that only
+// connects two neighbours in a chain into single edges that keep the full geometry. A node is
+// interstitial if it has one edge in from and one edge out to two other nodes, or edges in both
+// directions to and from exactly two other nodes.
+//
+// Nodes on ways referenced by a turn restriction are kept so restrictions still apply. Merged edges
+// take the way id of their first edge, list every way in WayIDs, combine the tags of the ways (see
+// mergeTags) and get the speed that keeps their travel time. Returns the number of nodes removed.
+func (g *Graph) Simplify() int {
+	restricted := map[int64]bool{}
+	for _, r := range g.Restrictions {
+		restricted[r.FromWay], restricted[r.ToWay] = true, true
+		for _, id := range r.ViaWays {
+			restricted[id] = true
+		}
+	}
+
+	removed := 0
+
+	for _, id := range g.NodeIDs() {
+		pairs, ok := g.interstitial(id, restricted)
+		if !ok {
+			continue
+		}
+
+		for _, pair := range pairs {
+			g.AddEdge(mergeEdges(pair[0], pair[1]))
+		}
+
+		g.RemoveNode(id)
+		removed++
+	}
+
+	return removed
+}
+
+// Returns the pairs of edges into and out of an interstitial node, in travel order
+func (g *Graph) interstitial(id int64, restricted map[int64]bool) ([][2]*Edge, bool) {
+	in, out := g.InEdges(id), g.OutEdges(id)
+
+	for _, e := range append(append([]*Edge{}, in...), out...) {
+		if e.From == e.To {
+			return nil, false
+		}
+		for _, way := range e.WayIDs {
+			if restricted[way] {
+				return nil, false
+			}
+		}
+	}
+
+	switch {
+	case len(in) == 1 && len(out) == 1:
+		// A chain in one direction, a dead end if it goes back to the same neighbour
+		if in[0].From == out[0].To {
+			return nil, false
+		}
+		return [][2]*Edge{{in[0], out[0]}}, true
+
+	case len(in) == 2 && len(out) == 2:
+		// Both directions between two distinct neighbours u and w
+		u, w := in[0].From, in[1].From
+		if u == w {
+			return nil, false
+		}
+
+		var uw, wu [2]*Edge
+		uw[0], wu[0] = in[0], in[1]
+
+		for _, e := range out {
+			switch e.To {
+			case w:
+				uw[1] = e
+			case u:
+				wu[1] = e
+			}
+		}
+
+		if uw[1] == nil || wu[1] == nil {
+			return nil, false
+		}
+
+		return [][2]*Edge{uw, wu}, true
+	}
+
+	return nil, false
+}
+
+// Returns the tags of both edges, values that differ are joined with ";" in order without
+// duplicates as in OSM multi-value tags, e.g. name=Main Street;High Street. a is returned as is if
+// b has the same tags.
+func mergeTags(a map[string]string, b map[string]string) map[string]string {
+	if reflect.DeepEqual(a, b) {
+		return a
+	}
+
+	tags := make(map[string]string, len(a))
+
+	for k, v := range a {
+		tags[k] = v
+	}
+
+	for k, v := range b {
+		existing, ok := tags[k]
+		if !ok {
+			tags[k] = v
+			continue
+		}
+
+		values := strings.Split(existing, ";")
+		for _, value := range strings.Split(v, ";") {
+			if !containsString(values, value) {
+				values = append(values, value)
+			}
+		}
+		tags[k] = strings.Join(values, ";")
+	}
+
+	return tags
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a new edge travelling a then b
+func mergeEdges(a *Edge, b *Edge) *Edge {
+	e := &Edge{
+		From:     a.From,
+		To:       b.To,
+		WayID:    a.WayID,
+		WayIDs:   append(append([]int64{}, a.WayIDs...), b.WayIDs...),
+		Geometry: append(append([][]float64{}, a.Geometry...), b.Geometry[1:]...),
+		Length:   a.Length + b.Length,
+		Oneway:   a.Oneway && b.Oneway,
+		Reversed: a.Reversed,
+		Tags:     mergeTags(a.Tags, b.Tags),
+	}
+
+	if a.Speed > 0 && b.Speed > 0 && e.Length > 0 {
+		e.Speed = e.Length / (a.Length/a.Speed + b.Length/b.Speed)
+	} else {
+		e.Speed = math.Min(a.Speed, b.Speed)
+	}
+
+	return e
+}
+
+// Merges the nodes within tolerance metres of each other (transitively) into a single node at
+// their centroid, e.g. the several nodes of a dual carriageway intersection. The merged node keeps
+// the lowest id of the cluster, edges between nodes of a cluster are removed and edges leaving or
+// entering a cluster are moved to its node with their geometry adjusted. Returns the number of nodes
+// removed.
+func (g *Graph) ConsolidateIntersections(tolerance float64) int {
+	ids := g.NodeIDs()
+	if len(ids) == 0 || tolerance <= 0 {
+		return 0
+	}
+
+	parent := map[int64]int64{}
+	find := func(id int64) int64 {
+		for parent[id] != id {
+			parent[id] = parent[parent[id]]
+			id = parent[id]
+		}
+		return id
+	}
+
+	// Bucket the nodes into a grid of tolerance sized cells, only neighbouring cells can be close
+	lat := 0.0
+	for _, id := range ids {
+		lat += g.Nodes[id].Lat
+	}
+	projection := helpers.NewLocalProjection(lat / float64(len(ids)))
+
+	type cell struct{ x, y int64 }
+	grid := map[cell][]int64{}
+
+	for _, id := range ids {
+		parent[id] = id
+		p := projection.Project([]float64{g.Nodes[id].Lon, g.Nodes[id].Lat})
+		c := cell{int64(math.Floor(p[0] / tolerance)), int64(math.Floor(p[1] / tolerance))}
+		grid[c] = append(grid[c], id)
+	}
+
+	for _, id := range ids {
+		n := g.Nodes[id]
+		p := projection.Project([]float64{n.Lon, n.Lat})
+		c := cell{int64(math.Floor(p[0] / tolerance)), int64(math.Floor(p[1] / tolerance))}
+
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, other := range grid[cell{c.x + dx, c.y + dy}] {
+					m := g.Nodes[other]
+					if other > id && helpers.Haversine(n.Lat, n.Lon, m.Lat, m.Lon) <= tolerance {
+						a, b := find(id), find(other)
+						if a > b {
+							a, b = b, a
+						}
+						parent[b] = a
+					}
+				}
+			}
+		}
+	}
+
+	clusters := map[int64][]int64{}
+	for _, id := range ids {
+		root := find(id)
+		clusters[root] = append(clusters[root], id)
+	}
+
+	removed := 0
+
+	for _, root := range ids {
+		members := clusters[root]
+		if len(members) < 2 {
+			continue
+		}
+
+		lat, lon := 0.0, 0.0
+		for _, id := range members {
+			lat += g.Nodes[id].Lat
+			lon += g.Nodes[id].Lon
+		}
+		lat, lon = lat/float64(len(members)), lon/float64(len(members))
+
+		g.Nodes[root] = &Node{ID: root, Lat: lat, Lon: lon, Tags: g.Nodes[root].Tags}
+
+		for _, id := range members {
+			for _, e := range append(append([]*Edge{}, g.OutEdges(id)...), g.InEdges(id)...) {
+				if _, ok := g.Edges[e.ID]; !ok {
+					continue
+				}
+
+				g.RemoveEdge(e.ID)
+
+				if find(e.From) == root && find(e.To) == root {
+					continue
+				}
+
+				moved := *e
+				moved.Geometry = append([][]float64{}, e.Geometry...)
+
+				if find(e.From) == root {
+					moved.From = root
+					moved.Geometry[0] = []float64{lon, lat}
+				}
+
+				if find(e.To) == root {
+					moved.To = root
+					moved.Geometry[len(moved.Geometry)-1] = []float64{lon, lat}
+				}
+
+				moved.Length = helpers.LineLength(moved.Geometry)
+				g.AddEdge(&moved)
+			}
+		}
+
+		for _, id := range members {
+			if id != root {
+				g.RemoveNode(id)
+				removed++
+			}
+		}
+	}
+
+	for _, r := range g.Restrictions {
+		if r.ViaNode != 0 {
+			if _, ok := parent[r.ViaNode]; ok {
+				r.ViaNode = find(r.ViaNode)
+			}
+		}
+	}
+
+	return removed
+}
+
+// Removes every edge starting and ending at the same node, returns the number of edges removed
+func (g *Graph) RemoveSelfLoops() int {
+	removed := 0
+
+	for _, e := range g.EdgeList() {
+		if e.From == e.To {
+			g.RemoveEdge(e.ID)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Removes parallel edges, keeping only the shortest edge from each node to each other node.
+// Returns the number of edges removed.
+func (g *Graph) RemoveParallelEdges() int {
+	type pair struct{ from, to int64 }
+	shortest := map[pair]*Edge{}
+	removed := 0
+
+	for _, e := range g.EdgeList() {
+		p := pair{e.From, e.To}
+
+		if s, ok := shortest[p]; ok {
+			if e.Length < s.Length {
+				shortest[p], e = e, s
+			}
+			g.RemoveEdge(e.ID)
+			removed++
+			continue
+		}
+
+		shortest[p] = e
+	}
+
+	return removed
+}
+
+// Returns the weakly connected components of the graph, largest first, each as ascending node ids
+func (g *Graph) WeaklyConnectedComponents() [][]int64 {
+	seen := map[int64]bool{}
+	var components [][]int64
+
+	for _, id := range g.NodeIDs() {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		component := []int64{}
+		stack := []int64{id}
+
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, n)
+
+			for _, e := range g.OutEdges(n) {
+				if !seen[e.To] {
+					seen[e.To] = true
+					stack = append(stack, e.To)
+				}
+			}
+
+			for _, e := range g.InEdges(n) {
+				if !seen[e.From] {
+					seen[e.From] = true
+					stack = append(stack, e.From)
+				}
+			}
+		}
+
+		components = append(components, component)
+	}
+
+	return sortComponents(components)
+}
+
+// Returns the strongly connected components of the graph, largest first, each as ascending node
+// ids. In a strongly connected component every node can be reached from every other node.
+func (g *Graph) StronglyConnectedComponents() [][]int64 {
+	// Kosaraju's algorithm, order the nodes by finishing time then search the reversed graph
+	seen := map[int64]bool{}
+	var order []int64
+
+	type frame struct {
+		id   int64
+		next int
+	}
+
+	for _, id := range g.NodeIDs() {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		stack := []frame{{id, 0}}
+
+		for len(stack) > 0 {
+			f := &stack[len(stack)-1]
+			out := g.OutEdges(f.id)
+
+			if f.next < len(out) {
+				to := out[f.next].To
+				f.next++
+				if !seen[to] {
+					seen[to] = true
+					stack = append(stack, frame{to, 0})
+				}
+				continue
+			}
+
+			order = append(order, f.id)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	assigned := map[int64]bool{}
+	var components [][]int64
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if assigned[order[i]] {
+			continue
+		}
+
+		assigned[order[i]] = true
+		component := []int64{}
+		stack := []int64{order[i]}
+
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, n)
+
+			for _, e := range g.InEdges(n) {
+				if !assigned[e.From] {
+					assigned[e.From] = true
+					stack = append(stack, e.From)
+				}
+			}
+		}
+
+		components = append(components, component)
+	}
+
+	return sortComponents(components)
+}
+
+// Returns the subgraph of the largest strongly or weakly connected component, ties are broken by
+// the lowest node id
+func (g *Graph) LargestComponent(strongly bool) *Graph {
+	var components [][]int64
+
+	if strongly {
+		components = g.StronglyConnectedComponents()
+	} else {
+		components = g.WeaklyConnectedComponents()
+	}
+
+	if len(components) == 0 {
+		return g.Subgraph(nil)
+	}
+
+	return g.Subgraph(components[0])
+}
+
+func sortComponents(components [][]int64) [][]int64 {
+	for _, c := range components {
+		sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		if len(components[i]) != len(components[j]) {
+			return len(components[i]) > len(components[j])
+		}
+		return components[i][0] < components[j][0]
+	})
+
+	return components
+}
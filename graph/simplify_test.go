@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	osm "github.com/captchanjack/osmdata"
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// A two-way street of three ways joining a oneway street of two ways at node 4, and a separate
+// street of two ways whose end nodes 8 and 9 are 4 metres apart:
+//
+//	1 --- 2 --- 3 --- 4   (ways 40, 41, 42)      8 9 --- 7   (ways 46, 47)
+//	                  |
+//	                  5   (way 43, oneway 4 -> 5)
+//	                  |
+//	                  6   (way 44, oneway 5 -> 6)
+const testChainNetwork = `{
+  "elements": [
+    {"type": "node", "id": 1, "lat": 0, "lon": 0.000},
+    {"type": "node", "id": 2, "lat": 0, "lon": 0.001},
+    {"type": "node", "id": 3, "lat": 0, "lon": 0.002},
+    {"type": "node", "id": 4, "lat": 0, "lon": 0.003},
+    {"type": "node", "id": 5, "lat": -0.001, "lon": 0.003},
+    {"type": "node", "id": 6, "lat": -0.002, "lon": 0.003},
+    {"type": "node", "id": 7, "lat": 0, "lon": 0.011},
+    {"type": "node", "id": 8, "lat": 0, "lon": 0.01},
+    {"type": "node", "id": 9, "lat": 0, "lon": 0.01004},
+    {"type": "way", "id": 40, "nodes": [1, 2], "tags": {"highway": "residential"}},
+    {"type": "way", "id": 41, "nodes": [2, 3], "tags": {"highway": "residential", "name": "Main Street"}},
+    {"type": "way", "id": 42, "nodes": [3, 4], "tags": {"highway": "residential", "maxspeed": "50", "name": "High Street"}},
+    {"type": "way", "id": 43, "nodes": [4, 5], "tags": {"highway": "residential", "oneway": "yes"}},
+    {"type": "way", "id": 44, "nodes": [5, 6], "tags": {"highway": "residential", "oneway": "yes"}},
+    {"type": "way", "id": 46, "nodes": [8, 9], "tags": {"highway": "service"}},
+    {"type": "way", "id": 47, "nodes": [9, 7], "tags": {"highway": "service"}}
+  ]
+}`
+
+func TestSimplify(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testChainNetwork)
+
+	before, err := g.ShortestPath(1, 6, TravelTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if removed := g.Simplify(); removed != 4 {
+		t.Errorf("expected 4 nodes removed, got %d", removed)
+	}
+
+	if ids := g.NodeIDs(); !reflect.DeepEqual(ids, []int64{1, 4, 6, 7, 8}) {
+		t.Errorf("expected nodes [1 4 6 7 8], got %v", ids)
+	}
+
+	if len(g.Edges) != 5 {
+		t.Errorf("expected 5 edges, got %d", len(g.Edges))
+	}
+
+	after, err := g.ShortestPath(1, 6, TravelTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if math.Abs(before.Weight-after.Weight) > 1e-6 || math.Abs(before.Length-after.Length) > 1e-6 {
+		t.Errorf("expected travel time %v and length %v to be kept, got %v and %v", before.Weight, before.Length, after.Weight, after.Length)
+	}
+
+	first := after.Edges[0]
+
+	if !reflect.DeepEqual(first.WayIDs, []int64{40, 41, 42}) || len(first.Geometry) != 4 || first.WayID != 40 {
+		t.Errorf("unexpected merged edge: way %d, ways %v, geometry %v", first.WayID, first.WayIDs, first.Geometry)
+	}
+
+	if tags := first.Tags; tags["highway"] != "residential" || tags["maxspeed"] != "50" || tags["name"] != "Main Street;High Street" {
+		t.Errorf("expected the tags of every merged way, got %v", tags)
+	}
+
+	if !after.Edges[1].Oneway {
+		t.Errorf("expected merged oneway edge to be oneway")
+	}
+
+	// Nodes on restricted ways are kept
+	g = buildTestGraph(t, osm.Drive, testChainNetwork)
+	g.AddRestriction(&Restriction{Type: "no_u_turn", FromWay: 41, ViaNode: 3, ToWay: 41})
+
+	if removed := g.Simplify(); removed != 2 {
+		t.Errorf("expected 2 nodes removed around restricted way, got %d", removed)
+	}
+}
+
+func TestConsolidateIntersections(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testChainNetwork)
+
+	if removed := g.ConsolidateIntersections(10); removed != 1 {
+		t.Errorf("expected 1 node removed, got %d", removed)
+	}
+
+	n, ok := g.Nodes[8]
+	if !ok || math.Abs(n.Lon-0.01002) > 1e-9 {
+		t.Fatalf("expected node 8 at the centroid, got %v", n)
+	}
+
+	out := g.OutEdges(8)
+	if len(out) != 1 || out[0].To != 7 || !reflect.DeepEqual(out[0].Geometry[0], []float64{n.Lon, n.Lat}) {
+		t.Fatalf("expected a single edge from the centroid to node 7, got %v", out)
+	}
+
+	expected := helpers.Haversine(n.Lat, n.Lon, 0, 0.011)
+	if math.Abs(out[0].Length-expected) > 1e-6 {
+		t.Errorf("expected length %v, got %v", expected, out[0].Length)
+	}
+}
+
+func TestRemoveSelfLoopsAndParallelEdges(t *testing.T) {
+	g := New(osm.Drive)
+	g.AddNode(&Node{ID: 1})
+	g.AddNode(&Node{ID: 2})
+	g.AddEdge(&Edge{From: 1, To: 1, Length: 5})
+	g.AddEdge(&Edge{From: 1, To: 2, Length: 20})
+	short := g.AddEdge(&Edge{From: 1, To: 2, Length: 10})
+	g.AddEdge(&Edge{From: 2, To: 1, Length: 30})
+
+	if removed := g.RemoveSelfLoops(); removed != 1 {
+		t.Errorf("expected 1 self-loop removed, got %d", removed)
+	}
+
+	if removed := g.RemoveParallelEdges(); removed != 1 {
+		t.Errorf("expected 1 parallel edge removed, got %d", removed)
+	}
+
+	if out := g.OutEdges(1); len(out) != 1 || out[0] != short {
+		t.Errorf("expected only the shortest edge from 1 to 2, got %v", out)
+	}
+
+	if len(g.Edges) != 2 {
+		t.Errorf("expected 2 edges, got %d", len(g.Edges))
+	}
+}
+
+func TestConnectedComponents(t *testing.T) {
+	g := buildTestGraph(t, osm.Drive, testChainNetwork)
+
+	weak := g.WeaklyConnectedComponents()
+	if !reflect.DeepEqual(weak, [][]int64{{1, 2, 3, 4, 5, 6}, {7, 8, 9}}) {
+		t.Errorf("unexpected weakly connected components %v", weak)
+	}
+
+	strong := g.StronglyConnectedComponents()
+	if !reflect.DeepEqual(strong, [][]int64{{1, 2, 3, 4}, {7, 8, 9}, {5}, {6}}) {
+		t.Errorf("unexpected strongly connected components %v", strong)
+	}
+
+	largest := g.LargestComponent(true)
+	if ids := largest.NodeIDs(); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4}) || len(largest.Edges) != 6 {
+		t.Errorf("unexpected largest component nodes %v with %d edges", ids, len(largest.Edges))
+	}
+}
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	osm "github.com/captchanjack/osmdata"
+)
+
+// Default speeds in km/h by highway or railway value, used when a way has no usable maxspeed
+var DefaultSpeeds = map[string]float64{
+	"motorway":       100,
+	"motorway_link":  60,
+	"trunk":          80,
+	"trunk_link":     50,
+	"primary":        60,
+	"primary_link":   40,
+	"secondary":      50,
+	"secondary_link": 40,
+	"tertiary":       40,
+	"tertiary_link":  30,
+	"unclassified":   40,
+	"residential":    30,
+	"living_street":  10,
+	"service":        20,
+	"road":           30,
+	"track":          15,
+	"rail":           100,
+	"light_rail":     60,
+	"subway":         60,
+	"tram":           30,
+	"monorail":       60,
+	"funicular":      15,
+}
+
+// Speeds in km/h for ways without a default speed, and for the Walk and Bike network types
+const (
+	FallbackSpeed = 30.0
+	WalkSpeed     = 5.0
+	BikeSpeed     = 15.0
+)
+
+// Speeds in km/h of the implicit maxspeed values, e.g. DE:urban or maxspeed=walk
+var implicitSpeeds = map[string]float64{
+	"urban":         50,
+	"rural":         90,
+	"trunk":         100,
+	"motorway":      120,
+	"living_street": 10,
+	"bicycle_road":  30,
+	"walk":          6,
+}
+
+// Parses a maxspeed value into km/h, handling mph and knots units, implicit country values such as
+// DE:urban and lists separated by ;, of which the lowest speed is returned. ok is false for none,
+// signals and values that can't be parsed.
+func ParseMaxspeed(value string) (speed float64, ok bool) {
+	speed = math.Inf(1)
+
+	for _, v := range strings.Split(value, ";") {
+		v = strings.TrimSpace(v)
+		factor := 1.0
+
+		switch {
+		case strings.HasSuffix(v, "mph"):
+			v, factor = strings.TrimSpace(strings.TrimSuffix(v, "mph")), 1.609344
+		case strings.HasSuffix(v, "knots"):
+			v, factor = strings.TrimSpace(strings.TrimSuffix(v, "knots")), 1.852
+		case strings.HasSuffix(v, "km/h"):
+			v = strings.TrimSpace(strings.TrimSuffix(v, "km/h"))
+		}
+
+		if i := strings.Index(v, ":"); i >= 0 {
+			v = v[i+1:]
+		}
+
+		s, err := strconv.ParseFloat(v, 64)
+
+		if err != nil {
+			var implicit bool
+			if s, implicit = implicitSpeeds[v]; !implicit {
+				continue
+			}
+		}
+
+		if s > 0 && s*factor < speed {
+			speed, ok = s*factor, true
+		}
+	}
+
+	if !ok {
+		return 0, false
+	}
+
+	return speed, true
+}
+
+// Returns the speed in km/h a way with these tags is travelled at by the network type. Walk and
+// Bike use WalkSpeed and BikeSpeed (capped by maxspeed for bikes), the other types use the
+// directional or plain maxspeed, falling back to DefaultSpeeds and then FallbackSpeed.
+func Speed(tags map[string]string, mode osm.PresetNetworkType, reversed bool) float64 {
+	if mode == osm.Walk {
+		return WalkSpeed
+	}
+
+	maxspeed, ok := 0.0, false
+
+	key := "maxspeed:forward"
+	if reversed {
+		key = "maxspeed:backward"
+	}
+
+	if maxspeed, ok = ParseMaxspeed(tags[key]); !ok {
+		maxspeed, ok = ParseMaxspeed(tags["maxspeed"])
+	}
+
+	if mode == osm.Bike {
+		if ok && maxspeed < BikeSpeed {
+			return maxspeed
+		}
+		return BikeSpeed
+	}
+
+	if ok {
+		return maxspeed
+	}
+
+	if s, ok := DefaultSpeeds[tags["highway"]]; ok {
+		return s
+	}
+
+	if s, ok := DefaultSpeeds[tags["railway"]]; ok {
+		return s
+	}
+
+	return FallbackSpeed
+}
@@ -0,0 +1,190 @@
+package helpers
+
+import (
+	"math"
+	"sort"
+)
+
+// Returns the signed area of a ring of [lon, lat] coordinates using the shoelace formula in
+// degrees squared, positive for counter-clockwise rings
+func SignedRingArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		area += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return area / 2
+}
+
+// Returns true if point [lon, lat] lies inside the ring of [lon, lat] coordinates (even-odd rule)
+func PointInRing(point []float64, ring [][]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > point[1]) != (yj > point[1]) && point[0] < (xj-xi)*(point[1]-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Reverses coordinates in place
+func ReverseCoordinates(coordinates [][]float64) {
+	for i, j := 0, len(coordinates)-1; i < j; i, j = i+1, j-1 {
+		coordinates[i], coordinates[j] = coordinates[j], coordinates[i]
+	}
+}
+
+// Mean radius of the earth in metres
+const EarthRadius = 6371008.8
+
+// Returns the great-circle distance in metres between two points
+func Haversine(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Returns the length in metres of a line of [lon, lat] coordinates
+func LineLength(coordinates [][]float64) float64 {
+	length := 0.0
+	for i := 1; i < len(coordinates); i++ {
+		length += Haversine(coordinates[i-1][1], coordinates[i-1][0], coordinates[i][1], coordinates[i][0])
+	}
+	return length
+}
+
+// Returns the [lon, lat] point the given distance in metres along a line of [lon, lat] coordinates,
+// clamped to the ends of the line
+func InterpolateLine(coordinates [][]float64, distance float64) []float64 {
+	if distance <= 0 {
+		return coordinates[0]
+	}
+
+	for i := 1; i < len(coordinates); i++ {
+		a, b := coordinates[i-1], coordinates[i]
+		length := Haversine(a[1], a[0], b[1], b[0])
+
+		if distance <= length && length > 0 {
+			f := distance / length
+			return []float64{a[0] + f*(b[0]-a[0]), a[1] + f*(b[1]-a[1])}
+		}
+
+		distance -= length
+	}
+
+	return coordinates[len(coordinates)-1]
+}
+
+// LocalProjection maps [lon, lat] coordinates to planar [x, y] metres with an equirectangular
+// projection around a reference latitude, accurate enough over city scale distances
+type LocalProjection struct {
+	kx float64
+	ky float64
+}
+
+// Returns a pointer to a new LocalProjection around the reference latitude
+func NewLocalProjection(lat float64) *LocalProjection {
+	p := new(LocalProjection)
+	p.ky = EarthRadius * math.Pi / 180
+	p.kx = p.ky * math.Cos(lat*math.Pi/180)
+	return p
+}
+
+// Returns the [x, y] metres of a [lon, lat] coordinate
+func (p *LocalProjection) Project(coordinate []float64) []float64 {
+	return []float64{coordinate[0] * p.kx, coordinate[1] * p.ky}
+}
+
+// Returns the [lon, lat] coordinate of [x, y] metres
+func (p *LocalProjection) Unproject(point []float64) []float64 {
+	return []float64{point[0] / p.kx, point[1] / p.ky}
+}
+
+// Returns the convex hull of planar [x, y] points as a closed counter-clockwise ring, nil if the
+// points are all collinear
+func ConvexHull(points [][]float64) [][]float64 {
+	sorted := append([][]float64{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+
+	cross := func(o, a, b []float64) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+
+	// Andrew's monotone chain, lower hull then upper hull
+	hull := [][]float64{}
+	for _, pass := range []int{1, -1} {
+		start := len(hull)
+		for k := range sorted {
+			p := sorted[k]
+			if pass == -1 {
+				p = sorted[len(sorted)-1-k]
+			}
+			for len(hull) >= start+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		hull = hull[:len(hull)-1]
+	}
+
+	if len(hull) < 3 {
+		return nil
+	}
+
+	return append(hull, hull[0])
+}
+
+// Returns the convex hull of [lon, lat] points grown outwards by buffer metres as a closed
+// counter-clockwise ring of [lon, lat] coordinates, nil if the hull has no area
+func BufferedHull(points [][]float64, buffer float64) [][]float64 {
+	if len(points) == 0 {
+		return nil
+	}
+
+	lat := 0.0
+	for _, p := range points {
+		lat += p[1]
+	}
+	projection := NewLocalProjection(lat / float64(len(points)))
+
+	projected := make([][]float64, len(points))
+	for i, p := range points {
+		projected[i] = projection.Project(p)
+	}
+
+	if buffer > 0 {
+		// Only the hull vertices can contribute to the hull of the buffered points
+		if hull := ConvexHull(projected); hull != nil {
+			projected = hull[:len(hull)-1]
+		}
+
+		const segments = 16
+		buffered := make([][]float64, 0, len(projected)*segments)
+		for _, p := range projected {
+			for k := 0; k < segments; k++ {
+				// Circumscribed polygon so the buffer is never less than the distance
+				angle := 2 * math.Pi * float64(k) / segments
+				r := buffer / math.Cos(math.Pi/segments)
+				buffered = append(buffered, []float64{p[0] + r*math.Cos(angle), p[1] + r*math.Sin(angle)})
+			}
+		}
+		projected = buffered
+	}
+
+	hull := ConvexHull(projected)
+	for i, p := range hull {
+		hull[i] = projection.Unproject(p)
+	}
+
+	return hull
+}
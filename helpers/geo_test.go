@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	var testCases = []struct {
+		points   [][]float64
+		vertices int
+	}{
+		{[][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0.5, 0.5}}, 4},
+		{[][]float64{{0, 0}, {2, 0}, {1, 0}, {1, 1}}, 3},
+		{[][]float64{{0, 0}, {1, 1}, {2, 2}}, 0},
+		{[][]float64{{0, 0}, {0, 0}}, 0},
+	}
+
+	for _, test := range testCases {
+		hull := ConvexHull(test.points)
+
+		if test.vertices == 0 {
+			if hull != nil {
+				t.Errorf("%v: expected no hull, got %v", test.points, hull)
+			}
+			continue
+		}
+
+		if len(hull) != test.vertices+1 || SignedRingArea(hull) <= 0 {
+			t.Errorf("%v: expected closed counter-clockwise hull of %d vertices, got %v", test.points, test.vertices, hull)
+		}
+	}
+}
+
+func TestBufferedHull(t *testing.T) {
+	points := [][]float64{{144.93, -37.74}, {144.94, -37.74}}
+
+	if hull := BufferedHull(points, 0); hull != nil {
+		t.Errorf("expected no hull for two points, got %v", hull)
+	}
+
+	hull := BufferedHull(points, 100)
+
+	// A point 90 metres north of the first point is within the buffer
+	north := []float64{144.93, -37.74 + 90/(EarthRadius*math.Pi/180)}
+
+	if hull == nil || !PointInRing(north, hull) {
+		t.Errorf("expected buffered hull containing %v, got %v", north, hull)
+	}
+}
+
+func TestInterpolateLine(t *testing.T) {
+	line := [][]float64{{0, 0}, {0.001, 0}, {0.001, 0.001}}
+	segment := Haversine(0, 0, 0, 0.001)
+
+	var testCases = []struct {
+		distance float64
+		point    []float64
+	}{
+		{-1, []float64{0, 0}},
+		{segment / 2, []float64{0.0005, 0}},
+		{segment * 1.5, []float64{0.001, 0.0005}},
+		{segment * 3, []float64{0.001, 0.001}},
+	}
+
+	for _, test := range testCases {
+		p := InterpolateLine(line, test.distance)
+
+		if math.Abs(p[0]-test.point[0]) > 1e-9 || math.Abs(p[1]-test.point[1]) > 1e-9 {
+			t.Errorf("%v: expected %v, got %v", test.distance, test.point, p)
+		}
+	}
+}
@@ -32,6 +32,18 @@ func JoinArrInt(a []int, delim ...string) string {
 	return strings.Join(b, _delim)
 }
 
+func JoinArrInt64(a []int64, delim ...string) string {
+	_delim := ","
+	if len(delim) > 0 {
+		_delim = delim[0]
+	}
+	b := make([]string, len(a))
+	for i, v := range a {
+		b[i] = strconv.FormatInt(v, 10)
+	}
+	return strings.Join(b, _delim)
+}
+
 func FormatHTTPGetURL(baseURL string, queryParams map[string]string) string {
 	base, err := url.Parse(baseURL)
 
@@ -50,21 +62,67 @@ func FormatHTTPGetURL(baseURL string, queryParams map[string]string) string {
 	return base.String()
 }
 
+// Calls fn with args, panics if fn is not a function, args do not match its signature or fn itself
+// panics. Untyped nil args are passed as the zero value of the parameter type.
 func ExecVaradicFunction(fn interface{}, args ...interface{}) []reflect.Value {
+	result, err := ExecVaradicFunctionE(fn, args...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// Same as ExecVaradicFunction but returns an error instead of panicking
+func ExecVaradicFunctionE(fn interface{}, args ...interface{}) (result []reflect.Value, err error) {
+	f := reflect.ValueOf(fn)
+
+	if f.Kind() != reflect.Func || f.IsNil() {
+		return nil, fmt.Errorf("cannot call %T, expected a function", fn)
+	}
+
+	t := f.Type()
+	numIn := t.NumIn()
+
+	if t.IsVariadic() && len(args) < numIn-1 || !t.IsVariadic() && len(args) != numIn {
+		return nil, fmt.Errorf("wrong number of args for %s: got %d", t, len(args))
+	}
+
 	// Convert arguments to reflect.Value
 	vs := make([]reflect.Value, len(args))
 
 	for n := range args {
+		var in reflect.Type
+
+		if t.IsVariadic() && n >= numIn-1 {
+			in = t.In(numIn - 1).Elem()
+		} else {
+			in = t.In(n)
+		}
+
+		if args[n] == nil {
+			switch in.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+				vs[n] = reflect.Zero(in)
+				continue
+			}
+			return nil, fmt.Errorf("arg %d of %s cannot be nil, expected %s", n, t, in)
+		}
+
 		vs[n] = reflect.ValueOf(args[n])
+
+		if !vs[n].Type().AssignableTo(in) {
+			return nil, fmt.Errorf("arg %d of %s has type %s, expected %s", n, t, vs[n].Type(), in)
+		}
 	}
 
-	// Recover message in case panic
+	// Guard against panics raised by fn itself
 	defer func() {
 		if r := recover(); r != nil {
-			panic(fmt.Sprintf("input args were incorrect %t, check if they match the signature of the varadic function: %t", args, fn))
+			result, err = nil, fmt.Errorf("%s panicked: %v", t, r)
 		}
 	}()
 
-	// Call it. Note it panics if func is not callable or arguments don't match
-	return reflect.ValueOf(fn).Call(vs)
+	return f.Call(vs), nil
 }
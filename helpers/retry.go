@@ -0,0 +1,222 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides whether, and after how long, a failed HTTP request is sent again.
+//
+// Implementations must be safe for concurrent use, a single policy is typically shared by every
+// request a client makes.
+type RetryPolicy interface {
+	// Returns how long to wait before the next attempt, retry is false to give up and return the
+	// last response or error to the caller
+	NextRetry(ctx context.Context, attempt RetryAttempt) (wait time.Duration, retry bool)
+}
+
+// Outcome of a failed attempt passed to a RetryPolicy
+type RetryAttempt struct {
+	Attempt  int            // Number of attempts made so far, starting at 1
+	Elapsed  time.Duration  // Time since the first attempt was started
+	Response *http.Response // Response with a non 2xx status code, nil if Err is set
+	Err      error          // Error returned by the attempt, nil if Response is set
+}
+
+// Errors implementing RetryAfterError carry their own wait, e.g. a rate limit known ahead of
+// sending the request, ExponentialBackoff honours it the same as a Retry-After header.
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// NoRetry never retries, every request is attempted exactly once
+var NoRetry RetryPolicy = noRetry{}
+
+type noRetry struct{}
+
+func (noRetry) NextRetry(ctx context.Context, attempt RetryAttempt) (time.Duration, bool) {
+	return 0, false
+}
+
+// ExponentialBackoff retries transport errors and responses with a retryable status code, waiting
+// InitialInterval * Multiplier^(attempt-1) (capped at MaxInterval) randomised by
+// RandomizationFactor between attempts. A Retry-After header takes precedence over the computed
+// interval.
+type ExponentialBackoff struct {
+	InitialInterval      time.Duration // Wait after the first failed attempt
+	MaxInterval          time.Duration // Upper bound of the computed wait, Retry-After is not capped
+	Multiplier           float64       // Factor the wait grows by after each attempt
+	RandomizationFactor  float64       // Jitter, the wait is picked uniformly from wait * [1 - factor, 1 + factor]
+	MaxElapsedTime       time.Duration // Give up once the next attempt would start after this long, zero means no limit
+	MaxAttempts          int           // Give up after this many attempts, zero means no limit
+	RetryableStatusCodes map[int]bool  // Status codes worth retrying, all other non 2xx responses are returned as-is
+	IgnoreRetryAfter     bool          // Do not honour Retry-After headers
+}
+
+// Returns a pointer to a new ExponentialBackoff retrying 429, 500, 502, 503 and 504 responses for up
+// to two minutes, starting at 500ms and growing by 2x up to 30s with 50% jitter.
+func NewExponentialBackoff() *ExponentialBackoff {
+	p := new(ExponentialBackoff)
+	p.InitialInterval = 500 * time.Millisecond
+	p.MaxInterval = 30 * time.Second
+	p.Multiplier = 2
+	p.RandomizationFactor = 0.5
+	p.MaxElapsedTime = 2 * time.Minute
+	p.RetryableStatusCodes = map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+	}
+	return p
+}
+
+func (p *ExponentialBackoff) NextRetry(ctx context.Context, attempt RetryAttempt) (time.Duration, bool) {
+	if p.MaxAttempts > 0 && attempt.Attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if attempt.Response != nil && !p.RetryableStatusCodes[attempt.Response.StatusCode] {
+		return 0, false
+	}
+
+	wait, ok := time.Duration(0), false
+
+	if !p.IgnoreRetryAfter {
+		if attempt.Response != nil {
+			wait, ok = ParseRetryAfter(attempt.Response, time.Now())
+		} else {
+			var e RetryAfterError
+			if errors.As(attempt.Err, &e) {
+				wait, ok = e.RetryAfter(), true
+			}
+		}
+	}
+
+	if !ok {
+		wait = p.interval(attempt.Attempt)
+	}
+
+	if p.MaxElapsedTime > 0 && attempt.Elapsed+wait > p.MaxElapsedTime {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+func (p *ExponentialBackoff) interval(attempt int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+
+	if p.RandomizationFactor > 0 {
+		delta := p.RandomizationFactor * interval
+		interval = interval - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(interval)
+}
+
+// Returns the wait requested by the Retry-After header of resp, given either in seconds or as an
+// HTTP date relative to now. ok is false if the header is missing or malformed.
+func ParseRetryAfter(resp *http.Response, now time.Time) (wait time.Duration, ok bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// Calls attempt until it returns a 2xx response or policy gives up, waiting between attempts as
+// directed by policy. A nil policy behaves like NoRetry.
+//
+// Bodies of responses that are retried are drained and closed, the final response is returned
+// untouched (even when its status code is not 2xx) and must be closed by the caller. Cancelling
+// ctx aborts any wait and returns ctx.Err().
+func Retry(ctx context.Context, policy RetryPolicy, attempt func(ctx context.Context) (*http.Response, error)) (*http.Response, error) {
+	if policy == nil {
+		policy = NoRetry
+	}
+
+	start := time.Now()
+
+	for i := 1; ; i++ {
+		resp, err := attempt(ctx)
+
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		wait, retry := policy.NextRetry(ctx, RetryAttempt{
+			Attempt:  i,
+			Elapsed:  time.Since(start),
+			Response: resp,
+			Err:      err,
+		})
+
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		if err := SleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Sleeps for duration d, returning ctx.Err() early if ctx is cancelled first
+func SleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	p := NewExponentialBackoff()
+	p.RandomizationFactor = 0
+	p.MaxAttempts = 4
+
+	tooMany := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	retryAfter := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"7"}}}
+	notFound := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}}
+
+	var testCases = []struct {
+		name    string
+		attempt RetryAttempt
+		wait    time.Duration
+		retry   bool
+	}{
+		{"first retry", RetryAttempt{Attempt: 1, Response: tooMany}, 500 * time.Millisecond, true},
+		{"third retry", RetryAttempt{Attempt: 3, Response: tooMany}, 2 * time.Second, true},
+		{"max attempts", RetryAttempt{Attempt: 4, Response: tooMany}, 0, false},
+		{"retry after", RetryAttempt{Attempt: 1, Response: retryAfter}, 7 * time.Second, true},
+		{"not retryable", RetryAttempt{Attempt: 1, Response: notFound}, 0, false},
+		{"transport error", RetryAttempt{Attempt: 2, Err: errors.New("connection reset")}, time.Second, true},
+		{"max elapsed", RetryAttempt{Attempt: 2, Elapsed: 2 * time.Minute, Response: tooMany}, 0, false},
+	}
+
+	for _, test := range testCases {
+		wait, retry := p.NextRetry(context.Background(), test.attempt)
+
+		if wait != test.wait || retry != test.retry {
+			t.Errorf("%v: expected (%v, %v), got (%v, %v)", test.name, test.wait, test.retry, wait, retry)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewExponentialBackoff()
+	p.InitialInterval = time.Millisecond
+
+	resp, err := Retry(context.Background(), p, func(ctx context.Context) (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		return http.DefaultClient.Do(req)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("expected success on the third attempt, got status %d after %d calls", resp.StatusCode, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = Retry(ctx, p, func(ctx context.Context) (*http.Response, error) {
+		return nil, errors.New("unreachable")
+	})
+
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,511 @@
+package helpers
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+type SimplifyMethod string
+
+const (
+	DouglasPeucker SimplifyMethod = "DouglasPeucker"
+	Visvalingam    SimplifyMethod = "Visvalingam"
+)
+
+// SimplifyOptions control how SimplifyPolygon and SimplifyMultiPolygon reduce the number of vertices
+type SimplifyOptions struct {
+	// DouglasPeucker (default) removes vertices closer than Tolerance metres to the simplified line,
+	// Visvalingam removes vertices whose triangle with their neighbours has an area below Tolerance
+	// squared square metres
+	Method    SimplifyMethod
+	Tolerance float64
+	// Extra distance in metres the simplified polygon is grown by, on top of what is needed to
+	// cover the original
+	Buffer float64
+	// Maximum number of coordinates across all rings, the tolerance is raised until the result
+	// fits and an error is returned if it cannot. 0 means no limit.
+	MaxVertices int
+}
+
+// Returns a pointer to a new SimplifyOptions using Douglas-Peucker with the tolerance in metres
+func NewSimplifyOptions(tolerance float64) *SimplifyOptions {
+	o := new(SimplifyOptions)
+	o.Method = DouglasPeucker
+	o.Tolerance = tolerance
+	return o
+}
+
+// Metres added to the offset of simplified rings so removed vertices lying exactly on a grown edge
+// are not left outside by rounding
+const coverMargin = 0.01
+
+// Returns a simplified copy of a line of [lon, lat] coordinates, the ends are always kept
+func SimplifyLine(coordinates [][]float64, method SimplifyMethod, tolerance float64) [][]float64 {
+	if len(coordinates) < 3 {
+		return append([][]float64{}, coordinates...)
+	}
+
+	projection := NewLocalProjection(meanLat(coordinates))
+	points := make([][]float64, len(coordinates))
+	for i, c := range coordinates {
+		points[i] = projection.Project(c)
+	}
+
+	keep := simplifyIndices(points, false, method, tolerance)
+
+	simplified := [][]float64{}
+	for i, c := range coordinates {
+		if keep[i] {
+			simplified = append(simplified, c)
+		}
+	}
+
+	return simplified
+}
+
+// Returns a simplified copy of GeoJSON Polygon coordinates that covers the original polygon: the
+// exterior ring is grown and the holes are shrunk by the largest distance a removed vertex lies
+// outside the simplified polygon, plus the buffer. Holes that vanish when shrunk are dropped, and
+// rings the offset would give more vertices than they have are kept as they are.
+// Growing uses mitred corners, so rings with spikes narrower than the tolerance may self-intersect.
+//
+// Returns the coarsest simplification found along with an error if it has more than MaxVertices.
+func SimplifyPolygon(polygon [][][]float64, options *SimplifyOptions) ([][][]float64, error) {
+	simplified, err := SimplifyMultiPolygon([][][][]float64{polygon}, options)
+	return simplified[0], err
+}
+
+// Same as SimplifyPolygon for each polygon of GeoJSON MultiPolygon coordinates, MaxVertices applies
+// to the multipolygon as a whole
+func SimplifyMultiPolygon(multiPolygon [][][][]float64, options *SimplifyOptions) ([][][][]float64, error) {
+	if options == nil {
+		options = NewSimplifyOptions(0)
+	}
+
+	tolerance := options.Tolerance
+	simplified := simplifyMultiPolygon(multiPolygon, options, tolerance)
+
+	if options.MaxVertices <= 0 {
+		return simplified, nil
+	}
+
+	if tolerance <= 0 {
+		tolerance = 1
+	}
+
+	if countVertices(simplified) <= options.MaxVertices {
+		return simplified, nil
+	}
+
+	// Double the tolerance until the result fits, bounded so an unreachable budget (e.g. fewer
+	// vertices than the rings need to stay closed) ends with the coarsest result instead of looping
+	// forever
+	low := tolerance
+	for i := 0; i < 48 && countVertices(simplified) > options.MaxVertices; i++ {
+		low, tolerance = tolerance, tolerance*2
+		simplified = simplifyMultiPolygon(multiPolygon, options, tolerance)
+	}
+
+	if vertices := countVertices(simplified); vertices > options.MaxVertices {
+		return simplified, fmt.Errorf("could not simplify to at most %d vertices, got %d", options.MaxVertices, vertices)
+	}
+
+	// Then bisect towards the smallest tolerance that fits to keep as much detail as the budget allows
+	for i := 0; i < 8 && countVertices(simplified) <= options.MaxVertices; i++ {
+		middle := (low + tolerance) / 2
+		if result := simplifyMultiPolygon(multiPolygon, options, middle); countVertices(result) <= options.MaxVertices {
+			simplified, tolerance = result, middle
+		} else {
+			low = middle
+		}
+	}
+
+	return simplified, nil
+}
+
+func simplifyMultiPolygon(multiPolygon [][][][]float64, options *SimplifyOptions, tolerance float64) [][][][]float64 {
+	simplified := make([][][][]float64, len(multiPolygon))
+
+	for i, polygon := range multiPolygon {
+		if len(polygon) == 0 {
+			simplified[i] = polygon
+			continue
+		}
+
+		projection := NewLocalProjection(meanLat(polygon[0]))
+		simplified[i] = [][][]float64{}
+
+		for j, ring := range polygon {
+			r := simplifyRing(ring, projection, options, tolerance, j > 0)
+
+			if r == nil && j == 0 {
+				// Degenerate exterior, nothing sensible to simplify
+				r = ring
+			}
+
+			if r != nil {
+				simplified[i] = append(simplified[i], r)
+			}
+		}
+	}
+
+	return simplified
+}
+
+// Simplifies a closed ring of [lon, lat] coordinates and offsets it so the result covers the
+// original polygon, nil if the ring is degenerate or, for holes, vanishes. Rounded corners of the
+// offset can outnumber the removed vertices, such rings are returned as they are without the buffer.
+func simplifyRing(ring [][]float64, projection *LocalProjection, options *SimplifyOptions, tolerance float64, hole bool) [][]float64 {
+	n := len(ring) - 1
+
+	if n < 3 {
+		return nil
+	}
+
+	points := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		points[i] = projection.Project(ring[i])
+	}
+
+	// Exteriors counter-clockwise and holes clockwise, so the side to grow towards is always right
+	reversed := signedArea(points) > 0 == hole
+	if reversed {
+		ReverseCoordinates(points)
+	}
+
+	keep := simplifyIndices(points, true, options.Method, tolerance)
+
+	kept := []int{}
+	for i, k := range keep {
+		if k {
+			kept = append(kept, i)
+		}
+	}
+
+	if len(kept) < 3 {
+		return nil
+	}
+
+	// Largest distance a removed vertex lies on the outer side of the edge replacing it
+	distance := 0.0
+	for k, from := range kept {
+		to := kept[(k+1)%len(kept)]
+		for i := (from + 1) % n; i != to; i = (i + 1) % n {
+			if d := rightDistance(points[i], points[from], points[to]); d > distance {
+				distance = d
+			}
+		}
+	}
+	distance += options.Buffer + coverMargin
+
+	simplified := make([][]float64, len(kept))
+	for k, i := range kept {
+		simplified[k] = points[i]
+	}
+
+	simplified, ok := offsetRing(simplified, distance)
+
+	// A hole shrunk by more than its width turns inside out
+	if hole && !ok || signedArea(simplified) >= 0 == hole {
+		return nil
+	}
+
+	if len(simplified) >= n {
+		return append([][]float64{}, ring...)
+	}
+
+	if reversed {
+		ReverseCoordinates(simplified)
+	}
+
+	coordinates := make([][]float64, len(simplified)+1)
+	for i, p := range simplified {
+		coordinates[i] = projection.Unproject(p)
+	}
+	coordinates[len(simplified)] = coordinates[0]
+
+	return coordinates
+}
+
+// Returns which of the planar points to keep. Closed rings do not repeat their first point and keep
+// at least 3 points, open lines always keep their ends.
+func simplifyIndices(points [][]float64, closed bool, method SimplifyMethod, tolerance float64) []bool {
+	if method == Visvalingam {
+		return visvalingam(points, closed, tolerance*tolerance)
+	}
+	return douglasPeucker(points, closed, tolerance)
+}
+
+func douglasPeucker(points [][]float64, closed bool, tolerance float64) []bool {
+	n := len(points)
+	keep := make([]bool, n)
+	keep[0] = true
+
+	type span struct{ from, to int }
+	var stack []span
+
+	if closed {
+		// Split the ring at the point farthest from the first one
+		far := 0
+		for i := range points {
+			if dist(points[0], points[i]) > dist(points[0], points[far]) {
+				far = i
+			}
+		}
+		keep[far] = true
+		stack = []span{{0, far}, {far, n}}
+	} else {
+		keep[n-1] = true
+		stack = []span{{0, n - 1}}
+	}
+
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		farthest, max := -1, tolerance
+		for i := s.from + 1; i < s.to; i++ {
+			if d := segmentDistance(points[i], points[s.from], points[s.to%n]); d > max {
+				farthest, max = i, d
+			}
+		}
+
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, span{s.from, farthest}, span{farthest, s.to})
+		}
+	}
+
+	if closed {
+		ensureTriangle(points, keep)
+	}
+
+	return keep
+}
+
+// Keeps the point farthest from the line through the kept points when fewer than 3 are left
+func ensureTriangle(points [][]float64, keep []bool) {
+	kept := []int{}
+	for i, k := range keep {
+		if k {
+			kept = append(kept, i)
+		}
+	}
+
+	for len(kept) < 3 && len(kept) < len(points) {
+		a, b := points[kept[0]], points[kept[len(kept)-1]]
+		farthest, max := -1, -1.0
+		for i := range points {
+			if d := segmentDistance(points[i], a, b); !keep[i] && d > max {
+				farthest, max = i, d
+			}
+		}
+		keep[farthest] = true
+		kept = append(kept, farthest)
+	}
+}
+
+type vwVertex struct {
+	index int
+	area  float64
+}
+
+type vwHeap []vwVertex
+
+func (h vwHeap) Len() int            { return len(h) }
+func (h vwHeap) Less(i, j int) bool  { return h[i].area < h[j].area }
+func (h vwHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *vwHeap) Push(x interface{}) { *h = append(*h, x.(vwVertex)) }
+func (h *vwHeap) Pop() interface{} {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return v
+}
+
+func visvalingam(points [][]float64, closed bool, tolerance float64) []bool {
+	n := len(points)
+	keep := make([]bool, n)
+	prev := make([]int, n)
+	next := make([]int, n)
+	area := make([]float64, n)
+
+	for i := range points {
+		keep[i] = true
+		prev[i] = (i - 1 + n) % n
+		next[i] = (i + 1) % n
+	}
+
+	h := &vwHeap{}
+	removable := func(i int) bool {
+		return closed || i != 0 && i != n-1
+	}
+	update := func(i int) {
+		if !removable(i) {
+			return
+		}
+		area[i] = triangleArea(points[prev[i]], points[i], points[next[i]])
+		heap.Push(h, vwVertex{i, area[i]})
+	}
+
+	for i := range points {
+		update(i)
+	}
+
+	remaining, minimum := n, 2
+	if closed {
+		minimum = 3
+	}
+	effective := 0.0
+
+	for h.Len() > 0 && remaining > minimum {
+		v := heap.Pop(h).(vwVertex)
+
+		// Skip removed vertices and areas outdated by the removal of a neighbour
+		if !keep[v.index] || v.area != area[v.index] {
+			continue
+		}
+
+		// Effective areas never decrease, so a vertex is not removed before ones it shielded
+		if v.area > effective {
+			effective = v.area
+		}
+		if effective >= tolerance {
+			break
+		}
+
+		keep[v.index] = false
+		remaining--
+		p, q := prev[v.index], next[v.index]
+		next[p], prev[q] = q, p
+		update(p)
+		update(q)
+	}
+
+	return keep
+}
+
+// Returns the ring grown by distance towards the right of its direction of travel. Corners turning
+// left are rounded with a circumscribed polygon so no point within distance is left out, corners
+// turning right are mitred. ok is false if the ring turned inside out, i.e. most of its grown edges
+// point against the edges they were offset from.
+func offsetRing(ring [][]float64, distance float64) (offset [][]float64, ok bool) {
+	n := len(ring)
+	normal := func(a, b []float64) []float64 {
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			return []float64{0, 0}
+		}
+		return []float64{dy / l, -dx / l}
+	}
+
+	// Index of the first point emitted for each vertex
+	first := make([]int, n)
+
+	for i, p := range ring {
+		first[i] = len(offset)
+
+		n1 := normal(ring[(i-1+n)%n], p)
+		n2 := normal(p, ring[(i+1)%n])
+		angle := math.Atan2(n1[0]*n2[1]-n1[1]*n2[0], n1[0]*n2[0]+n1[1]*n2[1])
+
+		if angle <= 0 {
+			sx, sy := n1[0]+n2[0], n1[1]+n2[1]
+			s := sx*sx + sy*sy
+
+			// Mitres of near reversals reach too far, fall back to a bevel
+			if s < 0.25 {
+				offset = append(offset,
+					[]float64{p[0] + n1[0]*distance, p[1] + n1[1]*distance},
+					[]float64{p[0] + n2[0]*distance, p[1] + n2[1]*distance},
+				)
+				continue
+			}
+
+			offset = append(offset, []float64{p[0] + 2*distance*sx/s, p[1] + 2*distance*sy/s})
+			continue
+		}
+
+		segments := math.Ceil(angle / (math.Pi / 2))
+		step := angle / segments
+		r := distance / math.Cos(step/2)
+		start := math.Atan2(n1[1], n1[0])
+
+		for k := 0.0; k < segments; k++ {
+			a := start + (k+0.5)*step
+			offset = append(offset, []float64{p[0] + r*math.Cos(a), p[1] + r*math.Sin(a)})
+		}
+	}
+
+	// Local folds at short edges reverse a few edges, a ring turned inside out reverses most of them
+	reversed, total := 0.0, 0.0
+	for i := range ring {
+		j := (i + 1) % n
+		a, b := offset[(first[j]-1+len(offset))%len(offset)], offset[first[j]]
+		l := dist(ring[i], ring[j])
+		total += l
+		if (b[0]-a[0])*(ring[j][0]-ring[i][0])+(b[1]-a[1])*(ring[j][1]-ring[i][1]) < 0 {
+			reversed += l
+		}
+	}
+	ok = reversed*2 < total
+
+	return offset, ok
+}
+
+func meanLat(coordinates [][]float64) float64 {
+	lat := 0.0
+	for _, c := range coordinates {
+		lat += c[1]
+	}
+	return lat / float64(len(coordinates))
+}
+
+func countVertices(multiPolygon [][][][]float64) int {
+	count := 0
+	for _, polygon := range multiPolygon {
+		for _, ring := range polygon {
+			count += len(ring)
+		}
+	}
+	return count
+}
+
+// Signed area of an open planar ring, positive for counter-clockwise rings
+func signedArea(points [][]float64) float64 {
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+func triangleArea(a, b, c []float64) float64 {
+	return math.Abs((b[0]-a[0])*(c[1]-a[1])-(c[0]-a[0])*(b[1]-a[1])) / 2
+}
+
+func dist(a, b []float64) float64 {
+	return math.Hypot(b[0]-a[0], b[1]-a[1])
+}
+
+// Distance from p to the segment between a and b
+func segmentDistance(p, a, b []float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	l := dx*dx + dy*dy
+	if l == 0 {
+		return dist(p, a)
+	}
+	t := math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/l))
+	return dist(p, []float64{a[0] + t*dx, a[1] + t*dy})
+}
+
+// Distance from p to the segment between a and b when p is to the right of it, 0 otherwise
+func rightDistance(p, a, b []float64) float64 {
+	if (b[0]-a[0])*(p[1]-a[1])-(b[1]-a[1])*(p[0]-a[0]) >= 0 {
+		return 0
+	}
+	return segmentDistance(p, a, b)
+}
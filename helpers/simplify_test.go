@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns a closed ring of [lon, lat] coordinates around a centre with a radius in metres that
+// wobbles by the amplitude in metres, counter-clockwise unless reversed
+func testWobblyRing(lon float64, lat float64, radius float64, amplitude float64, vertices int, reversed bool) [][]float64 {
+	projection := NewLocalProjection(lat)
+	centre := projection.Project([]float64{lon, lat})
+	ring := make([][]float64, vertices+1)
+
+	for i := 0; i < vertices; i++ {
+		a := 2 * math.Pi * float64(i) / float64(vertices)
+		r := radius + amplitude*math.Sin(float64(i)*1.7)*math.Cos(float64(i)*0.31)
+		ring[i] = projection.Unproject([]float64{centre[0] + r*math.Cos(a), centre[1] + r*math.Sin(a)})
+	}
+	ring[vertices] = ring[0]
+
+	if reversed {
+		ReverseCoordinates(ring)
+	}
+
+	return ring
+}
+
+func TestSimplifyLine(t *testing.T) {
+	line := [][]float64{{144.93, -37.74}, {144.935, -37.74}, {144.94, -37.74}, {144.94, -37.73}}
+
+	for _, method := range []SimplifyMethod{DouglasPeucker, Visvalingam} {
+		simplified := SimplifyLine(line, method, 1)
+
+		if len(simplified) != 3 || simplified[1][0] != 144.94 {
+			t.Errorf("%v: expected the collinear point to be removed, got %v", method, simplified)
+		}
+	}
+}
+
+func TestSimplifyPolygon(t *testing.T) {
+	polygon := [][][]float64{
+		testWobblyRing(144.95, -37.8, 5000, 40, 2000, false),
+		testWobblyRing(144.95, -37.8, 1000, 40, 500, true),
+	}
+
+	var testCases = []struct {
+		options *SimplifyOptions
+		holes   int
+	}{
+		{NewSimplifyOptions(50), 1},
+		{&SimplifyOptions{Method: Visvalingam, Tolerance: 50, Buffer: 10}, 1},
+		{&SimplifyOptions{Method: DouglasPeucker, Tolerance: 2000}, 1},
+		{&SimplifyOptions{Method: DouglasPeucker, Tolerance: 50, Buffer: 1500}, 0},
+		{&SimplifyOptions{MaxVertices: 60}, -1},
+	}
+
+	for _, test := range testCases {
+		simplified, err := SimplifyPolygon(polygon, test.options)
+
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", *test.options, err)
+		}
+
+		vertices := 0
+		for _, ring := range simplified {
+			vertices += len(ring)
+		}
+
+		if vertices >= len(polygon[0]) || test.options.MaxVertices > 0 && vertices > test.options.MaxVertices {
+			t.Errorf("%+v: expected fewer vertices, got %d", *test.options, vertices)
+		}
+
+		if test.holes >= 0 && len(simplified)-1 != test.holes {
+			t.Errorf("%+v: expected %d holes, got %d", *test.options, test.holes, len(simplified)-1)
+		}
+
+		for _, p := range polygon[0] {
+			if !PointInRing(p, simplified[0]) {
+				t.Errorf("%+v: expected %v to be covered by the simplified exterior", *test.options, p)
+				break
+			}
+		}
+
+		// Simplified holes must lie within the original hole
+		for _, hole := range simplified[1:] {
+			for _, p := range hole {
+				if !PointInRing(p, polygon[1]) {
+					t.Errorf("%+v: expected %v to be within the original hole", *test.options, p)
+					break
+				}
+			}
+			for _, p := range polygon[1] {
+				if PointInRing(p, hole) {
+					t.Errorf("%+v: expected %v to be outside the simplified hole", *test.options, p)
+					break
+				}
+			}
+		}
+	}
+	// Offsetting a triangle rounds each corner with two vertices, so it is kept as it is
+	triangle := [][][]float64{{{144.9, -37.8}, {144.91, -37.8}, {144.905, -37.79}, {144.9, -37.8}}}
+
+	if simplified, err := SimplifyPolygon(triangle, NewSimplifyOptions(1)); err != nil || len(simplified[0]) > len(triangle[0]) {
+		t.Errorf("expected at most %d vertices, got %v (%v)", len(triangle[0]), simplified, err)
+	}
+
+	// A closed ring needs at least 4 coordinates
+	simplified, err := SimplifyPolygon(polygon, &SimplifyOptions{MaxVertices: 3})
+
+	if err == nil || len(simplified) == 0 {
+		t.Errorf("expected error and the coarsest simplification for an unreachable budget, got %v (%v)", simplified, err)
+	}
+}
@@ -0,0 +1,80 @@
+package nominatim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores successful responses keyed by request URL
+type Cache interface {
+	Get(key string) (value []byte, ok bool)
+	Set(key string, value []byte) error
+}
+
+// FileCache persists responses as files in a directory so they survive restarts
+type FileCache struct {
+	Dir    string        // Directory holding one file per response
+	MaxAge time.Duration // Responses older than this are fetched again, zero means they never expire
+}
+
+// Returns a pointer to a new FileCache in dir, creating the directory if needed
+func NewFileCache(dir string, maxAge time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	c := new(FileCache)
+	c.Dir = dir
+	c.MaxAge = maxAge
+	return c, nil
+}
+
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	filename := c.filename(key)
+
+	if c.MaxAge > 0 {
+		info, err := os.Stat(filename)
+		if err != nil || time.Since(info.ModTime()) > c.MaxAge {
+			return nil, false
+		}
+	}
+
+	value, err := os.ReadFile(filename)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Writes to a temporary file first so concurrent readers never see a partial response
+func (c *FileCache) Set(key string, value []byte) error {
+	f, err := os.CreateTemp(c.Dir, "*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), c.filename(key))
+}
+
+func (c *FileCache) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,274 @@
+package nominatim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+const (
+	DefaultBaseURL   = "https://nominatim.openstreetmap.org"
+	DefaultUserAgent = "osmdata (+https://github.com/captchanjack/osmdata)"
+)
+
+// DefaultRetryPolicy is the RetryPolicy of clients created by NewClient
+var DefaultRetryPolicy helpers.RetryPolicy = helpers.NewExponentialBackoff()
+
+// DefaultLimiter allows one request per second as required by the usage policy of the public
+// Nominatim instance, it is shared by every client created by NewClient
+var DefaultLimiter = NewRateLimiter(time.Second)
+
+// Client sends requests to a Nominatim instance.
+//
+// The zero value is not usable, construct one with NewClient. Fields may be modified after
+// construction but not while requests are in flight.
+type Client struct {
+	BaseURL     string              // Root of the instance, e.g. https://nominatim.openstreetmap.org
+	HTTPClient  *http.Client        // HTTP client used for every request
+	UserAgent   string              // User-Agent header identifying the application, required
+	Email       string              // Contact address sent with every request, recommended for bulk use
+	Timeout     time.Duration       // Default timeout applied to each HTTP request, zero means no timeout
+	RetryPolicy helpers.RetryPolicy // Policy deciding which failed requests are sent again, nil means no retries
+	Limiter     *RateLimiter        // Limiter every request waits on, nil means no limit
+	Cache       Cache               // Cache of successful responses, nil means no caching
+	// Called when a response cannot be written to Cache, the response is still returned to the
+	// caller. nil ignores such failures.
+	OnCacheError func(url string, err error)
+}
+
+// DefaultClient is used by Search, QueryNominatim and the other package level functions, it
+// identifies itself with DefaultUserAgent
+var DefaultClient = NewClient(DefaultBaseURL, DefaultUserAgent)
+
+// Returns a pointer to a new Client for the instance at baseURL, identifying itself with the
+// userAgent, using http.DefaultClient, DefaultRetryPolicy and DefaultLimiter and no cache. The
+// public instance requires a user agent identifying the application, requests are refused with an
+// error if it is empty.
+func NewClient(baseURL string, userAgent string) *Client {
+	c := new(Client)
+	c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	c.HTTPClient = http.DefaultClient
+	c.UserAgent = userAgent
+	c.RetryPolicy = DefaultRetryPolicy
+	c.Limiter = DefaultLimiter
+	return c
+}
+
+// Searches for a place name, returning every result in order of relevance. Without options the
+// results include address details, extra tags, name details and the decoded geometry, see
+// NewSearchOptions.
+func (c *Client) Search(placeName string, options ...*SearchOptions) ([]NominatimItem, error) {
+	return c.SearchContext(context.Background(), placeName, options...)
+}
+
+// Same as Search, cancelling ctx aborts the request and any wait and returns ctx.Err()
+func (c *Client) SearchContext(ctx context.Context, placeName string, options ...*SearchOptions) ([]NominatimItem, error) {
+	params := searchOptions(options).params()
+	params["q"] = placeName
+
+	var result []NominatimItem
+
+	if err := c.getJSON(ctx, "search", params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Searches by address parts, e.g. a postcode within a country, returning every result in order of
+// relevance. Without options the results are the same as for Search.
+func (c *Client) SearchStructured(query *StructuredQuery, options ...*SearchOptions) ([]NominatimItem, error) {
+	return c.SearchStructuredContext(context.Background(), query, options...)
+}
+
+// Same as SearchStructured, cancelling ctx aborts the request and any wait and returns ctx.Err()
+func (c *Client) SearchStructuredContext(ctx context.Context, query *StructuredQuery, options ...*SearchOptions) ([]NominatimItem, error) {
+	params, err := query.params()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range searchOptions(options).params() {
+		params[k] = v
+	}
+
+	var result []NominatimItem
+
+	if err := c.getJSON(ctx, "search", params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func searchOptions(options []*SearchOptions) *SearchOptions {
+	if len(options) > 0 && options[0] != nil {
+		return options[0]
+	}
+	return NewSearchOptions()
+}
+
+// Returns every polygon of the most relevant search result with a Polygon or MultiPolygon geometry,
+// each as an exterior ring followed by its holes
+func (c *Client) QueryPolygons(placeName string) (polygons [][][][]float64, err error) {
+	return c.QueryPolygonsContext(context.Background(), placeName)
+}
+
+// Same as QueryPolygons, cancelling ctx aborts the request and any wait and returns ctx.Err()
+func (c *Client) QueryPolygonsContext(ctx context.Context, placeName string) (polygons [][][][]float64, err error) {
+	result, err := c.SearchContext(ctx, placeName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range result {
+		if polygons := item.GeoJSON.Polygons(); len(polygons) > 0 {
+			return polygons, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find suitable polygon for input place name '%s'", placeName)
+}
+
+// Sends a GET request to the endpoint (e.g. search) and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, endpoint string, params map[string]string, v interface{}) error {
+	body, err := c.get(ctx, endpoint, params)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode Nominatim response: %w", err)
+	}
+
+	return nil
+}
+
+// Sends a GET request to the endpoint, answering from the cache when possible, waiting on the
+// limiter before every attempt and retrying according to the RetryPolicy
+func (c *Client) get(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	if c.UserAgent == "" {
+		return nil, fmt.Errorf("nominatim requires a User-Agent identifying the application")
+	}
+
+	if c.Email != "" {
+		params["email"] = c.Email
+	}
+
+	url := helpers.FormatHTTPGetURL(c.BaseURL+"/"+endpoint, params)
+
+	if c.Cache != nil {
+		if body, ok := c.Cache.Get(url); ok {
+			return body, nil
+		}
+	}
+
+	resp, err := helpers.Retry(ctx, c.RetryPolicy, func(ctx context.Context) (*http.Response, error) {
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		return c.do(ctx, url)
+	})
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("nominatim error (status code %v):\n%s", resp.StatusCode, body)
+	}
+
+	// Failed lookups, e.g. reverse geocoding with nothing there, are answered with 200 and an error
+	// object, they are not cached so they are tried again next time
+	if c.Cache != nil && !isErrorBody(body) {
+		if err := c.Cache.Set(url, body); err != nil && c.OnCacheError != nil {
+			c.OnCacheError(url, fmt.Errorf("encountered error during Nominatim cache write: %w", err))
+		}
+	}
+
+	return body, nil
+}
+
+// Reports whether a response body is a Nominatim error object, e.g. {"error": "Unable to geocode"}
+func isErrorBody(body []byte) bool {
+	trimmed := strings.TrimSpace(string(body))
+
+	if !strings.HasPrefix(trimmed, "{") {
+		return false
+	}
+
+	var result struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	return json.Unmarshal(body, &result) == nil && len(result.Error) > 0 && string(result.Error) != "null"
+}
+
+func (c *Client) do(ctx context.Context, url string) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{resp.Body, cancel}
+	return resp, nil
+}
+
+// Releases the request's timeout once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,207 @@
+package nominatim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a test server answering every request with testSearchResponse, and a client for it
+// without rate limit or retries
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *Client) {
+	if handler == nil {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(testSearchResponse))
+		}
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/", "osmdata-test")
+	client.Limiter = nil
+	client.RetryPolicy = nil
+
+	return server, client
+}
+
+func TestClientSearch(t *testing.T) {
+	var userAgent, email, path, query string
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent, email, path = r.UserAgent(), r.URL.Query().Get("email"), r.URL.Path
+		query = r.URL.Query().Get("q")
+		w.Write([]byte(testSearchResponse))
+	})
+	client.Email = "test@example.com"
+
+	items, err := client.Search("melbourne")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(items) != 2 || items[0].OSMID != 2402 {
+		t.Errorf("unexpected items %+v", items)
+	}
+
+	if userAgent != "osmdata-test" || email != "test@example.com" || path != "/search" || query != "melbourne" {
+		t.Errorf("unexpected request: user agent %q, email %q, path %q, query %q", userAgent, email, path, query)
+	}
+
+	polygons, err := client.QueryPolygons("melbourne")
+
+	if err != nil || len(polygons) != 2 {
+		t.Errorf("expected 2 polygons, got %d (%v)", len(polygons), err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked", http.StatusForbidden)
+	})
+
+	if _, err := client.Search("melbourne"); err == nil {
+		t.Errorf("expected error for status 403")
+	}
+
+	client.UserAgent = ""
+
+	if _, err := client.Search("melbourne"); err == nil {
+		t.Errorf("expected error without a user agent")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	var requests int32
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(testSearchResponse))
+	})
+	client.Limiter = NewRateLimiter(50 * time.Millisecond)
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Search("melbourne"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected 3 requests to take at least 100ms, took %v", elapsed)
+	}
+
+	// A cancelled context aborts the wait for the next slot
+	client.Limiter = NewRateLimiter(time.Hour)
+	client.Limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SearchContext(ctx, "melbourne"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Cancelled waits give their slot back, so they do not delay later requests
+	limiter := NewRateLimiter(100 * time.Millisecond)
+	limiter.Wait(context.Background())
+	start = time.Now()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	}
+
+	limiter.Wait(context.Background())
+
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the next slot in 100ms after cancelled waits, took %v", elapsed)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	var requests int32
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(testSearchResponse))
+	})
+
+	cache, err := NewFileCache(t.TempDir(), 0)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		if items, err := client.Search("melbourne"); err != nil || len(items) != 2 {
+			t.Fatalf("expected 2 items, got %d (%v)", len(items), err)
+		}
+	}
+
+	client.Search("sydney")
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests with a cache hit, got %d", requests)
+	}
+
+	// Expired responses are fetched again
+	cache.MaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	client.Search("melbourne")
+
+	if requests != 3 {
+		t.Errorf("expected expired response to be fetched again, got %d requests", requests)
+	}
+
+	// Error objects answered with 200 are not cached
+	_, failing := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"error":"Unable to geocode"}`))
+	})
+	failing.Cache = cache
+	cache.MaxAge = 0
+
+	for i := 0; i < 2; i++ {
+		if _, err := failing.Reverse(-37.8, 144.9, nil); err == nil {
+			t.Errorf("expected reverse geocoding to fail")
+		}
+	}
+
+	if requests != 5 {
+		t.Errorf("expected error responses to be fetched again, got %d requests", requests)
+	}
+
+	// Failing to write the cache does not fail the request
+	var cacheErr error
+	client.Cache = &FileCache{Dir: filepath.Join(t.TempDir(), "missing")}
+	client.OnCacheError = func(url string, err error) { cacheErr = err }
+
+	if items, err := client.Search("perth"); err != nil || len(items) != 2 {
+		t.Errorf("expected 2 items despite the cache write failing, got %d (%v)", len(items), err)
+	}
+
+	if cacheErr == nil {
+		t.Errorf("expected the cache write failure to be reported")
+	}
+}
@@ -0,0 +1,82 @@
+package nominatim
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// RateLimiter spaces requests at least Interval apart, it is safe for concurrent use and may be
+// shared by several clients talking to the same instance
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time   // First slot after every reserved slot
+	free     []time.Time // Reserved slots given back by cancelled waits, in order
+}
+
+// Returns a pointer to a new RateLimiter allowing one request per interval
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	l := new(RateLimiter)
+	l.interval = interval
+	return l
+}
+
+// Blocks until the next request may be sent, cancelling ctx aborts the wait and returns ctx.Err().
+// The slot of a cancelled wait is given back for later waits to use.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.reserve(now)
+	l.mu.Unlock()
+
+	err := helpers.SleepContext(ctx, slot.Sub(now))
+
+	if err != nil {
+		l.mu.Lock()
+		l.release(slot)
+		l.mu.Unlock()
+	}
+
+	return err
+}
+
+// Returns the earliest slot given back that has not passed, otherwise reserves the next slot
+func (l *RateLimiter) reserve(now time.Time) time.Time {
+	for len(l.free) > 0 {
+		slot := l.free[0]
+		l.free = l.free[1:]
+
+		if !slot.Before(now) {
+			return slot
+		}
+	}
+
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+
+	return slot
+}
+
+// Gives back a reserved slot, the last slot moves next back along with any slots given back before
+// it, others are kept for reserve
+func (l *RateLimiter) release(slot time.Time) {
+	if !slot.Add(l.interval).Equal(l.next) {
+		i := sort.Search(len(l.free), func(i int) bool { return l.free[i].After(slot) })
+		l.free = append(l.free[:i], append([]time.Time{slot}, l.free[i:]...)...)
+		return
+	}
+
+	l.next = slot
+
+	for n := len(l.free); n > 0 && l.free[n-1].Add(l.interval).Equal(l.next); n-- {
+		l.next = l.free[n-1]
+		l.free = l.free[:n-1]
+	}
+}
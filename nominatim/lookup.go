@@ -0,0 +1,59 @@
+package nominatim
+
+import (
+	"context"
+	"strings"
+)
+
+// Maximum number of ids Nominatim accepts in a single lookup request
+const maxLookupIDs = 50
+
+// Looks up OSM objects by id, e.g. a suburb whose relation id is known, returning a result for each
+// object Nominatim knows in the order of the refs. More than 50 refs are split into several
+// requests. Without options the results include address details, extra tags, name details and the
+// decoded geometry, see NewLookupOptions. An error is returned without any request if a ref is
+// invalid, see OSMRef.Validate.
+func (c *Client) Lookup(refs []OSMRef, options ...*LookupOptions) ([]NominatimItem, error) {
+	return c.LookupContext(context.Background(), refs, options...)
+}
+
+// Same as Lookup, cancelling ctx aborts the request and any wait and returns ctx.Err()
+func (c *Client) LookupContext(ctx context.Context, refs []OSMRef, options ...*LookupOptions) ([]NominatimItem, error) {
+	o := NewLookupOptions()
+	if len(options) > 0 && options[0] != nil {
+		o = options[0]
+	}
+
+	for _, ref := range refs {
+		if err := ref.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := []NominatimItem{}
+
+	for start := 0; start < len(refs); start += maxLookupIDs {
+		end := start + maxLookupIDs
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		ids := make([]string, end-start)
+		for i, ref := range refs[start:end] {
+			ids[i] = ref.String()
+		}
+
+		params := o.params()
+		params["osm_ids"] = strings.Join(ids, ",")
+
+		var items []NominatimItem
+
+		if err := c.getJSON(ctx, "lookup", params, &items); err != nil {
+			return nil, err
+		}
+
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
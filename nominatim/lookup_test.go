@@ -0,0 +1,105 @@
+package nominatim
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestClientSearchOptions(t *testing.T) {
+	var query url.Values
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(testSearchResponse))
+	})
+
+	options := NewSearchOptions()
+	options.Limit = 10
+	options.CountryCodes = []string{"au", "nz"}
+	options.ViewBox = &ViewBox{MinLon: 144.5, MinLat: -38.5, MaxLon: 145.5, MaxLat: -37.5}
+	options.Bounded = true
+	options.ExcludePlaceIDs = []int64{1, 2}
+	options.ExtraTags = false
+	options.Language = "en"
+
+	if _, err := client.Search("melbourne", options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"q":                 "melbourne",
+		"limit":             "10",
+		"countrycodes":      "au,nz",
+		"viewbox":           "144.5,-38.5,145.5,-37.5",
+		"bounded":           "1",
+		"exclude_place_ids": "1,2",
+		"addressdetails":    "1",
+		"extratags":         "0",
+		"accept-language":   "en",
+	}
+
+	for k, v := range expected {
+		if query.Get(k) != v {
+			t.Errorf("search: expected %s=%s, got %s", k, v, query.Get(k))
+		}
+	}
+
+	if _, err := client.SearchStructured(&StructuredQuery{City: "Melbourne", PostalCode: "3000"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query.Get("city") != "Melbourne" || query.Get("postalcode") != "3000" || query.Get("limit") != "5" || query.Get("q") != "" || query.Get("street") != "" {
+		t.Errorf("structured search: unexpected query %v", query)
+	}
+
+	if _, err := client.SearchStructured(&StructuredQuery{}); err == nil {
+		t.Errorf("expected error for an empty structured query")
+	}
+}
+
+func TestClientLookup(t *testing.T) {
+	var batches []string
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		batches = append(batches, r.URL.Query().Get("osm_ids"))
+		w.Write([]byte(testSearchResponse))
+	})
+
+	refs := []OSMRef{{"relation", 2402}, {"way", 456}, {"node", 123}}
+	for i := 0; i < 57; i++ {
+		refs = append(refs, OSMRef{"node", int64(i + 1)})
+	}
+
+	items, err := client.Lookup(refs)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(batches) != 2 || !strings.HasPrefix(batches[0], "R2402,W456,N123,N1,") || strings.Count(batches[0], ",") != 49 || strings.Count(batches[1], ",") != 9 {
+		t.Errorf("expected batches of 50 and 10 ids, got %v", batches)
+	}
+
+	if len(items) != 4 {
+		t.Errorf("expected 4 items, got %d", len(items))
+	}
+
+	// Refs Nominatim would reject are reported before any request
+	batches = nil
+
+	for _, ref := range []OSMRef{{"", 2402}, {"area", 2402}, {"Relation", 0}} {
+		if _, err := client.Lookup([]OSMRef{{"way", 456}, ref}); err == nil {
+			t.Errorf("%v: expected an error for an invalid ref", ref)
+		}
+	}
+
+	if len(batches) != 0 {
+		t.Errorf("expected no requests for invalid refs, got %v", batches)
+	}
+
+	if ref := (OSMRef{"Relation", 2402}); ref.Validate() != nil || ref.String() != "R2402" {
+		t.Errorf("expected %v to be valid as R2402, got %s", ref, ref)
+	}
+}
@@ -1,84 +1,66 @@
 package nominatim
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-
-	"github.com/captchanjack/osmdata/helpers"
+	"context"
 )
 
-const nominatimSearchEndpoint = "https://nominatim.openstreetmap.org/search"
-
-func getNominatimSearchParams(placeName string) map[string]string {
-	return map[string]string{
-		"format":          "json",
-		"limit":           "5",
-		"dedupe":          "0",
-		"polygon_geojson": "1",
-		"q":               placeName,
-	}
-}
-
 type GeneralMap map[string]interface{}
 
-func QueryNominatim(placeName string) (coordinates [][][]float64, err error) {
-	url := helpers.FormatHTTPGetURL(
-		nominatimSearchEndpoint,
-		getNominatimSearchParams(placeName),
-	)
-
-	resp, err := http.Get(url)
-
-	if err != nil {
-		return [][][]float64{}, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
-	}
-
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-
-	if err != nil {
-		return [][][]float64{}, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
-	}
-
-	var result []NominatimItem
-	err = json.Unmarshal(body, &result)
+// Searches Nominatim for a place name through DefaultClient, see Client.Search
+func Search(placeName string, options ...*SearchOptions) ([]NominatimItem, error) {
+	return DefaultClient.SearchContext(context.Background(), placeName, options...)
+}
 
-	for i := range result {
-		item := &result[i]
+// Same as Search, cancelling ctx aborts the request and returns ctx.Err()
+func SearchContext(ctx context.Context, placeName string, options ...*SearchOptions) ([]NominatimItem, error) {
+	return DefaultClient.SearchContext(ctx, placeName, options...)
+}
 
-		switch item.GeoJSON.Type {
+// Searches Nominatim by address parts through DefaultClient, see Client.SearchStructured
+func SearchStructured(query *StructuredQuery, options ...*SearchOptions) ([]NominatimItem, error) {
+	return DefaultClient.SearchStructuredContext(context.Background(), query, options...)
+}
 
-		case "Polygon":
-			fmt.Printf("Using Polygon for %s\n", item.DisplayName)
-			err = json.Unmarshal(item.GeoJSON.Coordinates, &item.GeoJSON.Polygon.Coordinates)
+// Same as SearchStructured, cancelling ctx aborts the request and returns ctx.Err()
+func SearchStructuredContext(ctx context.Context, query *StructuredQuery, options ...*SearchOptions) ([]NominatimItem, error) {
+	return DefaultClient.SearchStructuredContext(ctx, query, options...)
+}
 
-			if err != nil {
-				fmt.Println(fmt.Errorf("%s: failed to convert %s: %s", item.DisplayName, item.GeoJSON.Type, err))
-				continue
-			}
+// Looks up OSM objects by id through DefaultClient, see Client.Lookup
+func Lookup(refs []OSMRef, options ...*LookupOptions) ([]NominatimItem, error) {
+	return DefaultClient.LookupContext(context.Background(), refs, options...)
+}
 
-			return item.GeoJSON.Polygon.Coordinates, nil
+// Same as Lookup, cancelling ctx aborts the request and returns ctx.Err()
+func LookupContext(ctx context.Context, refs []OSMRef, options ...*LookupOptions) ([]NominatimItem, error) {
+	return DefaultClient.LookupContext(ctx, refs, options...)
+}
 
-		case "MultiPolygon":
-			fmt.Printf("Using Polygon for %s\n", item.DisplayName)
-			err = json.Unmarshal(item.GeoJSON.Coordinates, &item.GeoJSON.MultiPolygon.Coordinates)
+// Returns the first polygon of the most relevant search result with a Polygon or MultiPolygon
+// geometry, the exterior ring followed by its holes. Use QueryNominatimPolygons to get every polygon
+// of a MultiPolygon.
+func QueryNominatim(placeName string) (coordinates [][][]float64, err error) {
+	return QueryNominatimContext(context.Background(), placeName)
+}
 
-			if err != nil {
-				fmt.Println(fmt.Errorf("%s: failed to convert %s: %s", item.DisplayName, item.GeoJSON.Type, err))
-				continue
-			}
+// Same as QueryNominatim, cancelling ctx aborts the request and returns ctx.Err()
+func QueryNominatimContext(ctx context.Context, placeName string) (coordinates [][][]float64, err error) {
+	polygons, err := QueryNominatimPolygonsContext(ctx, placeName)
 
-			// First element is the polygon, subsequent elements are holes
-			return item.GeoJSON.MultiPolygon.Coordinates[0], nil
+	if err != nil {
+		return [][][]float64{}, err
+	}
 
-		default:
-			fmt.Printf("unsupported GeoJSON type '%s'\n", item.GeoJSON.Type)
-			continue
+	return polygons[0], nil
+}
 
-		}
-	}
+// Returns every polygon of the most relevant search result with a Polygon or MultiPolygon geometry
+// through DefaultClient, e.g. a municipality and its islands, see Client.QueryPolygons
+func QueryNominatimPolygons(placeName string) (polygons [][][][]float64, err error) {
+	return QueryNominatimPolygonsContext(context.Background(), placeName)
+}
 
-	return [][][]float64{}, fmt.Errorf("could not find suitable polygon for input place name '%s'", placeName)
+// Same as QueryNominatimPolygons, cancelling ctx aborts the request and returns ctx.Err()
+func QueryNominatimPolygonsContext(ctx context.Context, placeName string) (polygons [][][][]float64, err error) {
+	return DefaultClient.QueryPolygonsContext(ctx, placeName)
 }
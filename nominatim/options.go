@@ -0,0 +1,183 @@
+package nominatim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// ViewBox is an area to prefer results in, or to restrict results to with SearchOptions.Bounded
+type ViewBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// SearchOptions configures a free-text or structured search, construct one with NewSearchOptions
+// to start from the defaults used when no options are passed
+type SearchOptions struct {
+	Limit           int      // Maximum number of results, at most 40, zero means the server default of 10
+	Dedupe          bool     // Merge results for the same place, e.g. the pieces of a long street
+	CountryCodes    []string // ISO 3166-1 alpha-2 codes of the countries to search, e.g. au, nz
+	ViewBox         *ViewBox // Area to prefer results in
+	Bounded         bool     // Only return results within ViewBox
+	ExcludePlaceIDs []int64  // Place ids of results to skip, e.g. those already seen on a previous page
+	AddressDetails  bool     // Include the address breakdown in NominatimItem.Address
+	PolygonGeoJSON  bool     // Include the geometry of results
+	ExtraTags       bool     // Include additional OSM tags in NominatimItem.ExtraTags
+	NameDetails     bool     // Include the name in every language in NominatimItem.NameDetails
+	Language        string   // Preferred languages of names, as an Accept-Language header e.g. en,de
+}
+
+// Returns a pointer to new SearchOptions with the defaults: up to 5 results without deduplication,
+// with address details, geometry, extra tags and name details
+func NewSearchOptions() *SearchOptions {
+	o := new(SearchOptions)
+	o.Limit = 5
+	o.AddressDetails = true
+	o.PolygonGeoJSON = true
+	o.ExtraTags = true
+	o.NameDetails = true
+	return o
+}
+
+func (o *SearchOptions) params() map[string]string {
+	params := detailParams(o.AddressDetails, o.PolygonGeoJSON, o.ExtraTags, o.NameDetails, o.Language)
+	params["dedupe"] = formatBool(o.Dedupe)
+
+	if o.Limit > 0 {
+		params["limit"] = strconv.Itoa(o.Limit)
+	}
+
+	if len(o.CountryCodes) > 0 {
+		params["countrycodes"] = strings.Join(o.CountryCodes, ",")
+	}
+
+	if o.ViewBox != nil {
+		params["viewbox"] = helpers.JoinArrFloat64([]float64{o.ViewBox.MinLon, o.ViewBox.MinLat, o.ViewBox.MaxLon, o.ViewBox.MaxLat})
+		params["bounded"] = formatBool(o.Bounded)
+	}
+
+	if len(o.ExcludePlaceIDs) > 0 {
+		params["exclude_place_ids"] = helpers.JoinArrInt64(o.ExcludePlaceIDs, ",")
+	}
+
+	return params
+}
+
+// StructuredQuery searches by address parts instead of free text, empty parts are left out
+type StructuredQuery struct {
+	Amenity    string // Name or type of a point of interest
+	Street     string // House number and street name
+	City       string
+	County     string
+	State      string
+	Country    string
+	PostalCode string
+}
+
+func (q *StructuredQuery) params() (map[string]string, error) {
+	params := map[string]string{}
+
+	for k, v := range map[string]string{
+		"amenity":    q.Amenity,
+		"street":     q.Street,
+		"city":       q.City,
+		"county":     q.County,
+		"state":      q.State,
+		"country":    q.Country,
+		"postalcode": q.PostalCode,
+	} {
+		if v != "" {
+			params[k] = v
+		}
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("structured query requires at least one address part")
+	}
+
+	return params, nil
+}
+
+// LookupOptions configures a lookup by OSM id, construct one with NewLookupOptions to start from
+// the defaults used when no options are passed
+type LookupOptions struct {
+	AddressDetails bool   // Include the address breakdown in NominatimItem.Address
+	PolygonGeoJSON bool   // Include the geometry of results
+	ExtraTags      bool   // Include additional OSM tags in NominatimItem.ExtraTags
+	NameDetails    bool   // Include the name in every language in NominatimItem.NameDetails
+	Language       string // Preferred languages of names, as an Accept-Language header e.g. en,de
+}
+
+// Returns a pointer to new LookupOptions with the defaults: address details, geometry, extra tags
+// and name details
+func NewLookupOptions() *LookupOptions {
+	o := new(LookupOptions)
+	o.AddressDetails = true
+	o.PolygonGeoJSON = true
+	o.ExtraTags = true
+	o.NameDetails = true
+	return o
+}
+
+func (o *LookupOptions) params() map[string]string {
+	return detailParams(o.AddressDetails, o.PolygonGeoJSON, o.ExtraTags, o.NameDetails, o.Language)
+}
+
+// OSMRef refers to an OSM node, way or relation
+type OSMRef struct {
+	Type string // node, way or relation, as in NominatimItem.OSMType
+	ID   int64
+}
+
+// Prefixes of each OSM type in lookup ids
+var osmRefPrefixes = map[string]string{
+	"node":     "N",
+	"way":      "W",
+	"relation": "R",
+}
+
+// Returns the reference in the form used by lookup, e.g. R2402, see Validate for the types
+// accepted
+func (r OSMRef) String() string {
+	return osmRefPrefixes[strings.ToLower(r.Type)] + strconv.FormatInt(r.ID, 10)
+}
+
+// Returns an error unless Type is node, way or relation (in any case) and ID is positive
+func (r OSMRef) Validate() error {
+	if _, ok := osmRefPrefixes[strings.ToLower(r.Type)]; !ok {
+		return fmt.Errorf("unknown OSM type %q of %d, expected node, way or relation", r.Type, r.ID)
+	}
+	if r.ID <= 0 {
+		return fmt.Errorf("invalid OSM id %d", r.ID)
+	}
+	return nil
+}
+
+// Returns the parameters selecting the details of results shared by every endpoint
+func detailParams(addressDetails bool, polygonGeoJSON bool, extraTags bool, nameDetails bool, language string) map[string]string {
+	params := map[string]string{
+		"format":          "json",
+		"addressdetails":  formatBool(addressDetails),
+		"polygon_geojson": formatBool(polygonGeoJSON),
+		"extratags":       formatBool(extraTags),
+		"namedetails":     formatBool(nameDetails),
+	}
+
+	if language != "" {
+		params["accept-language"] = language
+	}
+
+	return params
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,103 @@
+package nominatim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Returned (wrapped) when Nominatim finds nothing for a request
+var ErrNoResult = errors.New("nominatim found no result")
+
+// Zoom is the level of detail of a reverse geocoding result
+type Zoom int
+
+const (
+	ZoomCountry      Zoom = 3
+	ZoomState        Zoom = 5
+	ZoomCounty       Zoom = 8
+	ZoomCity         Zoom = 10
+	ZoomTown         Zoom = 12
+	ZoomSuburb       Zoom = 14
+	ZoomMajorStreets Zoom = 16
+	ZoomStreets      Zoom = 17
+	ZoomBuilding     Zoom = 18
+)
+
+// ReverseOptions configures a reverse geocoding request, a nil *ReverseOptions returns the
+// address of the closest building without geometry
+type ReverseOptions struct {
+	Zoom           Zoom   // Level of detail of the result, zero means ZoomBuilding
+	AddressDetails bool   // Include the address breakdown in NominatimItem.Address
+	PolygonGeoJSON bool   // Include the geometry of the result, e.g. the boundary polygon of a suburb
+	ExtraTags      bool   // Include additional OSM tags in NominatimItem.ExtraTags
+	NameDetails    bool   // Include the name in every language in NominatimItem.NameDetails
+	Language       string // Preferred languages of names, as an Accept-Language header e.g. en,de
+}
+
+func (o *ReverseOptions) params() map[string]string {
+	if o == nil {
+		o = &ReverseOptions{AddressDetails: true}
+	}
+
+	params := detailParams(o.AddressDetails, o.PolygonGeoJSON, o.ExtraTags, o.NameDetails, o.Language)
+
+	if o.Zoom != 0 {
+		params["zoom"] = strconv.Itoa(int(o.Zoom))
+	}
+
+	return params
+}
+
+// Returns the OSM object enclosing or closest to a point at the zoom level of the options, e.g.
+// the suburb with ZoomSuburb and PolygonGeoJSON whose boundary can be passed to
+// GetPresetQueryByPolygon. Returns an error wrapping ErrNoResult if there is nothing there.
+func (c *Client) Reverse(lat float64, lon float64, options *ReverseOptions) (*NominatimItem, error) {
+	return c.ReverseContext(context.Background(), lat, lon, options)
+}
+
+// Same as Reverse, cancelling ctx aborts the request and any wait and returns ctx.Err()
+func (c *Client) ReverseContext(ctx context.Context, lat float64, lon float64, options *ReverseOptions) (*NominatimItem, error) {
+	params := options.params()
+	params["lat"] = strconv.FormatFloat(lat, 'f', -1, 64)
+	params["lon"] = strconv.FormatFloat(lon, 'f', -1, 64)
+
+	body, err := c.get(ctx, "reverse", params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Nominatim answers 200 with an error object when nothing is found
+	var result struct {
+		Error string `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode Nominatim response: %w", err)
+	}
+
+	if result.Error != "" {
+		return nil, fmt.Errorf("reverse geocoding (%v, %v): %s: %w", lat, lon, result.Error, ErrNoResult)
+	}
+
+	item := new(NominatimItem)
+
+	if err := json.Unmarshal(body, item); err != nil {
+		return nil, fmt.Errorf("failed to decode Nominatim response: %w", err)
+	}
+
+	return item, nil
+}
+
+// Reverse geocodes a point through DefaultClient, see Client.Reverse
+func Reverse(lat float64, lon float64, options *ReverseOptions) (*NominatimItem, error) {
+	return DefaultClient.ReverseContext(context.Background(), lat, lon, options)
+}
+
+// Same as Reverse, cancelling ctx aborts the request and returns ctx.Err()
+func ReverseContext(ctx context.Context, lat float64, lon float64, options *ReverseOptions) (*NominatimItem, error) {
+	return DefaultClient.ReverseContext(ctx, lat, lon, options)
+}
@@ -0,0 +1,76 @@
+package nominatim
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+const testReverseResponse = `{
+  "place_id": 235,
+  "osm_type": "relation",
+  "osm_id": 2403,
+  "lat": "-37.74",
+  "lon": "144.93",
+  "class": "boundary",
+  "type": "administrative",
+  "place_rank": 20,
+  "address": {"suburb": "Brunswick West", "state": "Victoria"},
+  "geojson": {"type": "Polygon", "coordinates": [[[144.9, -37.8], [145.0, -37.8], [145.0, -37.7], [144.9, -37.8]]]}
+}`
+
+func TestClientReverse(t *testing.T) {
+	var query url.Values
+	var path string
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query, path = r.URL.Query(), r.URL.Path
+
+		if query.Get("lat") == "0" {
+			w.Write([]byte(`{"error": "Unable to geocode"}`))
+			return
+		}
+
+		w.Write([]byte(testReverseResponse))
+	})
+
+	item, err := client.Reverse(-37.74, 144.93, &ReverseOptions{Zoom: ZoomSuburb, AddressDetails: true, PolygonGeoJSON: true, Language: "en"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"lat":             "-37.74",
+		"lon":             "144.93",
+		"zoom":            "14",
+		"addressdetails":  "1",
+		"polygon_geojson": "1",
+		"extratags":       "0",
+		"accept-language": "en",
+		"format":          "json",
+	}
+
+	for k, v := range expected {
+		if query.Get(k) != v {
+			t.Errorf("expected %s=%s, got %s", k, v, query.Get(k))
+		}
+	}
+
+	if path != "/reverse" {
+		t.Errorf("expected path /reverse, got %s", path)
+	}
+
+	if item.OSMID != 2403 || item.Address["suburb"] != "Brunswick West" || len(item.GeoJSON.Polygons()) != 1 {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if _, err := client.Reverse(-37.74, 144.93, nil); err != nil || query.Get("zoom") != "" || query.Get("addressdetails") != "1" {
+		t.Errorf("unexpected default options %v (%v)", query, err)
+	}
+
+	if _, err := client.Reverse(0, 0, nil); !errors.Is(err, ErrNoResult) {
+		t.Errorf("expected ErrNoResult, got %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package nominatim
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
 
 type GeoJSONPoint struct {
 	Coordinates []float64
@@ -18,21 +22,135 @@ type GeoJSONMultiPolygon struct {
 	Coordinates [][][][]float64
 }
 
+// Geometry of a result, decoding fills the field matching Type (Point, LineString, Polygon or
+// MultiPolygon) from the raw Coordinates
 type GeoJSON struct {
-	Type         string          `json:"type"`
-	Coordinates  json.RawMessage `json:"coordinates"`
-	Point        GeoJSONPoint
-	Line         GeoJSONLine
-	Polygon      GeoJSONPolygon
-	MultiPolygon GeoJSONMultiPolygon
+	Type         string              `json:"type"`
+	Coordinates  json.RawMessage     `json:"coordinates"`
+	Point        GeoJSONPoint        `json:"-"`
+	Line         GeoJSONLine         `json:"-"`
+	Polygon      GeoJSONPolygon      `json:"-"`
+	MultiPolygon GeoJSONMultiPolygon `json:"-"`
 }
 
 type NominatimItem struct {
-	OSMType     int       `json:"osm_type"`
-	OSMID       int       `json:"osm_id"`
-	GeoJSON     GeoJSON   `json:"geojson"`
-	BoundingBox []float64 `json:"boundingbox"`
-	DisplayName string    `json:"display_name"`
-	Lat         float64   `json:"lat"`
-	Lon         float64   `json:"lon"`
+	PlaceID     int64             `json:"place_id"`
+	Licence     string            `json:"licence"`
+	OSMType     string            `json:"osm_type"` // node, way or relation
+	OSMID       int64             `json:"osm_id"`
+	GeoJSON     GeoJSON           `json:"geojson"`
+	BoundingBox []float64         `json:"boundingbox"` // [minLat, maxLat, minLon, maxLon]
+	DisplayName string            `json:"display_name"`
+	Name        string            `json:"name"`
+	Lat         float64           `json:"lat"`
+	Lon         float64           `json:"lon"`
+	Class       string            `json:"class"` // Main OSM tag key, e.g. boundary
+	Type        string            `json:"type"`  // Main OSM tag value, e.g. administrative
+	AddressType string            `json:"addresstype"`
+	PlaceRank   int               `json:"place_rank"`
+	Importance  float64           `json:"importance"`
+	Address     map[string]string `json:"address"`
+	ExtraTags   map[string]string `json:"extratags"`
+	NameDetails map[string]string `json:"namedetails"`
+}
+
+// Decodes the coordinates into the typed field for the geometry type
+func (g *GeoJSON) UnmarshalJSON(data []byte) error {
+	type alias GeoJSON
+	var aux alias
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	*g = GeoJSON(aux)
+
+	if len(g.Coordinates) == 0 {
+		return nil
+	}
+
+	var err error
+
+	switch g.Type {
+	case "Point":
+		err = json.Unmarshal(g.Coordinates, &g.Point.Coordinates)
+	case "LineString":
+		err = json.Unmarshal(g.Coordinates, &g.Line.Coordinates)
+	case "Polygon":
+		err = json.Unmarshal(g.Coordinates, &g.Polygon.Coordinates)
+	case "MultiPolygon":
+		err = json.Unmarshal(g.Coordinates, &g.MultiPolygon.Coordinates)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to decode %s coordinates: %w", g.Type, err)
+	}
+
+	return nil
+}
+
+// Returns every polygon of a Polygon or MultiPolygon geometry, each as an exterior ring followed by
+// its holes, nil for other geometry types
+func (g *GeoJSON) Polygons() [][][][]float64 {
+	switch g.Type {
+	case "Polygon":
+		return [][][][]float64{g.Polygon.Coordinates}
+	case "MultiPolygon":
+		return g.MultiPolygon.Coordinates
+	}
+	return nil
+}
+
+// Decodes a result, Nominatim sends the coordinates and bounding box as strings and the class as
+// category in the jsonv2 format
+func (item *NominatimItem) UnmarshalJSON(data []byte) error {
+	type alias NominatimItem
+	aux := struct {
+		*alias
+		Lat         json.Number   `json:"lat"`
+		Lon         json.Number   `json:"lon"`
+		BoundingBox []json.Number `json:"boundingbox"`
+		Importance  json.Number   `json:"importance"`
+		Category    string        `json:"category"`
+	}{alias: (*alias)(item)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+
+	if item.Lat, err = parseNumber(aux.Lat); err != nil {
+		return fmt.Errorf("invalid lat: %w", err)
+	}
+
+	if item.Lon, err = parseNumber(aux.Lon); err != nil {
+		return fmt.Errorf("invalid lon: %w", err)
+	}
+
+	if item.Importance, err = parseNumber(aux.Importance); err != nil {
+		return fmt.Errorf("invalid importance: %w", err)
+	}
+
+	item.BoundingBox = nil
+	for _, v := range aux.BoundingBox {
+		f, err := parseNumber(v)
+		if err != nil {
+			return fmt.Errorf("invalid boundingbox: %w", err)
+		}
+		item.BoundingBox = append(item.BoundingBox, f)
+	}
+
+	if item.Class == "" {
+		item.Class = aux.Category
+	}
+
+	return nil
+}
+
+func parseNumber(n json.Number) (float64, error) {
+	if n == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(string(n), 64)
 }
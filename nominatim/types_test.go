@@ -0,0 +1,84 @@
+package nominatim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testSearchResponse = `[
+  {
+    "place_id": 12345,
+    "licence": "Data © OpenStreetMap contributors, ODbL 1.0. http://osm.org/copyright",
+    "osm_type": "relation",
+    "osm_id": 2402,
+    "boundingbox": ["-37.85", "-37.75", "144.9", "145.0"],
+    "lat": "-37.8142176",
+    "lon": "144.9631608",
+    "display_name": "Melbourne, City of Melbourne, Victoria, Australia",
+    "class": "boundary",
+    "type": "administrative",
+    "place_rank": 16,
+    "importance": 0.83,
+    "addresstype": "city",
+    "name": "Melbourne",
+    "address": {"city": "Melbourne", "state": "Victoria", "country_code": "au"},
+    "extratags": {"wikidata": "Q3141"},
+    "namedetails": {"name": "Melbourne", "name:en": "Melbourne"},
+    "geojson": {"type": "MultiPolygon", "coordinates": [
+      [[[144.9, -37.8], [145.0, -37.8], [145.0, -37.7], [144.9, -37.8]]],
+      [[[145.1, -37.8], [145.2, -37.8], [145.2, -37.7], [145.1, -37.8]]]
+    ]}
+  },
+  {
+    "place_id": 67890,
+    "osm_type": "node",
+    "osm_id": 21579166,
+    "lat": "-37.8",
+    "lon": "144.9",
+    "category": "place",
+    "type": "city",
+    "importance": 0.5,
+    "geojson": {"type": "Point", "coordinates": [144.9, -37.8]}
+  }
+]`
+
+func TestNominatimItemUnmarshal(t *testing.T) {
+	var items []NominatimItem
+
+	if err := json.Unmarshal([]byte(testSearchResponse), &items); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	city := items[0]
+
+	if city.OSMType != "relation" || city.OSMID != 2402 || city.Lat != -37.8142176 || city.Lon != 144.9631608 {
+		t.Errorf("unexpected osm reference or coordinates: %+v", city)
+	}
+
+	if !reflect.DeepEqual(city.BoundingBox, []float64{-37.85, -37.75, 144.9, 145.0}) {
+		t.Errorf("unexpected bounding box %v", city.BoundingBox)
+	}
+
+	if city.Class != "boundary" || city.Type != "administrative" || city.PlaceRank != 16 || city.Importance != 0.83 {
+		t.Errorf("unexpected classification: %+v", city)
+	}
+
+	if city.Address["state"] != "Victoria" || city.ExtraTags["wikidata"] != "Q3141" || city.NameDetails["name:en"] != "Melbourne" {
+		t.Errorf("unexpected details: %v %v %v", city.Address, city.ExtraTags, city.NameDetails)
+	}
+
+	if polygons := city.GeoJSON.Polygons(); len(polygons) != 2 || len(polygons[1][0]) != 4 {
+		t.Errorf("expected 2 polygons, got %v", polygons)
+	}
+
+	node := items[1]
+
+	if node.Class != "place" || !reflect.DeepEqual(node.GeoJSON.Point.Coordinates, []float64{144.9, -37.8}) || node.GeoJSON.Polygons() != nil {
+		t.Errorf("unexpected point item: %+v", node)
+	}
+}
@@ -0,0 +1,47 @@
+package overpass
+
+import (
+	"bytes"
+	"context"
+)
+
+// Reports whether Overpass has derived the area, areas only exist for some relations and ways (see
+// AreaID) and are generated periodically, so very recent objects may not have one yet
+func (c *Client) AreaExists(areaID int64) (bool, error) {
+	return c.AreaExistsContext(context.Background(), areaID)
+}
+
+// Same as AreaExists, cancelling ctx aborts the request and returns ctx.Err()
+func (c *Client) AreaExistsContext(ctx context.Context, areaID int64) (bool, error) {
+	filter, err := NewElementFilterE(Int64IDFilter, areaID)
+
+	if err != nil {
+		return false, err
+	}
+
+	query := NewStackStatement(
+		NewSettingsStatement(*NewSetting(Out, JSON)),
+		NewElementStatement(Area, nil, filter),
+		NewOutStatement(Ids),
+	)
+
+	compiled, err := query.Compile()
+
+	if err != nil {
+		return false, err
+	}
+
+	body, err := c.QueryBytesContext(ctx, compiled)
+
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := DecodeJSON(bytes.NewReader(body))
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(resp.Areas()) > 0, nil
+}
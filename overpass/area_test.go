@@ -0,0 +1,63 @@
+package overpass
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAreaID(t *testing.T) {
+	var testCases = []struct {
+		elementType ElementType
+		id          int64
+		areaID      int64
+		ok          bool
+	}{
+		{Relation, 2402, 3600002402, true},
+		{Way, 24, 2400000024, true},
+		{Area, 3600002402, 3600002402, true},
+		{Node, 1, 0, false},
+	}
+
+	for _, test := range testCases {
+		areaID, err := AreaID(test.elementType, test.id)
+
+		if areaID != test.areaID || (err == nil) != test.ok {
+			t.Errorf("%v %v: expected %v (ok %v), got %v (%v)", test.elementType, test.id, test.areaID, test.ok, areaID, err)
+		}
+	}
+
+	way := NewElementStatement(Way, nil, NewElementFilter(AreaIDFilter, int64(3600002402)))
+
+	if err := way.Validate(); err != nil || way.GetCompiled() != "way(area:3600002402);" {
+		t.Errorf("unexpected area filter %v (%v)", way.GetCompiled(), err)
+	}
+
+	way = NewElementStatement(Way, nil, NewElementFilter(AreaFilter, 1, 2))
+
+	if err := way.Validate(); err != nil || way.GetCompiled() != "way(area:1,2);" {
+		t.Errorf("unexpected area filter %v (%v)", way.GetCompiled(), err)
+	}
+}
+
+func TestClientAreaExists(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if strings.Contains(string(body), "area(id:3600002402);out ids;") {
+			io.WriteString(w, `{"elements":[{"type":"area","id":3600002402}]}`)
+			return
+		}
+
+		io.WriteString(w, `{"elements":[]}`)
+	})
+
+	for areaID, expected := range map[int64]bool{3600002402: true, 3600000001: false} {
+		exists, err := client.AreaExists(areaID)
+
+		if err != nil || exists != expected {
+			t.Errorf("%v: expected %v, got %v (%v)", areaID, expected, exists, err)
+		}
+	}
+}
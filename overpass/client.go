@@ -0,0 +1,216 @@
+package overpass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+const (
+	DefaultInterpreterURL = "https://overpass-api.de/api/interpreter"
+	DefaultStatusURL      = "https://overpass-api.de/api/status"
+	DefaultUserAgent      = "osmdata (+https://github.com/captchanjack/osmdata)"
+)
+
+// Client executes queries against an Overpass API instance.
+//
+// The zero value is not usable, construct one with NewClient. Fields may be modified after
+// construction but not while queries are in flight.
+type Client struct {
+	BaseURL     string              // Interpreter endpoint, e.g. https://overpass-api.de/api/interpreter
+	StatusURL   string              // Status endpoint, e.g. https://overpass-api.de/api/status
+	HTTPClient  *http.Client        // HTTP client used for every request
+	UserAgent   string              // User-Agent header sent with every request
+	Timeout     time.Duration       // Default timeout applied to each HTTP request, zero means no timeout
+	RetryPolicy helpers.RetryPolicy // Policy deciding which failed queries are sent again, nil means no retries
+	CheckStatus bool                // Consult the status endpoint before each attempt and refuse to send without a free slot
+}
+
+// DefaultClient is used by QueryOverpass, QueryOverpassBytes and by StackStatement when no
+// client has been set.
+var DefaultClient = NewClient(DefaultInterpreterURL, DefaultStatusURL)
+
+// Returns a pointer to a new Client talking to the given interpreter and status endpoints, using
+// http.DefaultClient and DefaultUserAgent. The client always sends queries and does not retry
+// unless a RetryPolicy is set or maxAttempts is passed to a query.
+//
+// Set CheckStatus to consult the status endpoint before each attempt instead, an attempt made
+// without a free slot then fails with a *RateLimitError without being sent, which SlotPolicy
+// retries once the slot frees up.
+func NewClient(baseURL string, statusURL string) *Client {
+	c := new(Client)
+	c.BaseURL = baseURL
+	c.StatusURL = statusURL
+	c.HTTPClient = http.DefaultClient
+	c.UserAgent = DefaultUserAgent
+	return c
+}
+
+// Specify maxAttempts tp retry when rate limited, defaults to 1
+// Returns string
+func (c *Client) Query(query string, maxAttempts ...int) (response string, err error) {
+	return c.QueryContext(context.Background(), query, maxAttempts...)
+}
+
+// Same as Query, cancelling ctx aborts the request and any wait between attempts and returns
+// ctx.Err()
+func (c *Client) QueryContext(ctx context.Context, query string, maxAttempts ...int) (response string, err error) {
+	body, err := c.QueryBytesContext(ctx, query, maxAttempts...)
+	sb := string(body)
+	return sb, err
+}
+
+// Specify maxAttempts tp retry when rate limited, defaults to 1
+// Returns byte array
+func (c *Client) QueryBytes(query string, maxAttempts ...int) (response []byte, err error) {
+	return c.QueryBytesContext(context.Background(), query, maxAttempts...)
+}
+
+// Same as QueryBytes, cancelling ctx aborts the request and any wait between attempts and returns
+// ctx.Err()
+//
+// Failures reported by the server are returned as *RateLimitError, *QueryTimeoutError,
+// *OutOfMemoryError, *RuntimeError, *SyntaxError or *HTTPError, including runtime errors Overpass
+// embeds as remarks in otherwise successful responses.
+//
+// Failed attempts are retried according to the client's RetryPolicy. Passing maxAttempts retries
+// with a SlotPolicy of that many attempts instead, waiting for the next free slot whenever the
+// status endpoint reports none or the server responds 429 or 504.
+func (c *Client) QueryBytesContext(ctx context.Context, query string, maxAttempts ...int) (response []byte, err error) {
+	stream, err := c.QueryStreamContext(ctx, query, maxAttempts...)
+
+	if err != nil {
+		return make([]byte, 0), err
+	}
+
+	defer stream.Close()
+	body, err := io.ReadAll(stream)
+
+	if ctx.Err() != nil {
+		return make([]byte, 0), ctx.Err()
+	}
+
+	if err != nil {
+		return make([]byte, 0), fmt.Errorf("encountered error during POST request to Overpass API: %w", err)
+	}
+
+	if err := responseError(http.StatusOK, body); err != nil {
+		return make([]byte, 0), err
+	}
+
+	return body, nil
+}
+
+// Sends the query and returns the response body unread once the server has accepted the query,
+// the caller must close it. Retries and errors are handled as for QueryBytesContext, except that
+// runtime error remarks within the body are left to the reader (see ElementDecoder).
+func (c *Client) QueryStreamContext(ctx context.Context, query string, maxAttempts ...int) (io.ReadCloser, error) {
+	policy := c.RetryPolicy
+	if len(maxAttempts) > 0 {
+		policy = NewSlotPolicy(c, maxAttempts[0])
+	}
+
+	resp, err := helpers.Retry(ctx, policy, func(ctx context.Context) (*http.Response, error) {
+		if c.CheckStatus {
+			if wait, ok := c.slotWait(ctx); ok && wait > 0 {
+				return nil, &RateLimitError{Wait: wait}
+			}
+		}
+		return c.do(ctx, http.MethodPost, c.BaseURL, strings.NewReader(query))
+	})
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if _, ok := err.(*RateLimitError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("encountered error during POST request to Overpass API: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, responseError(resp.StatusCode, body)
+	}
+
+	return resp.Body, nil
+}
+
+// Returns how long to wait for the next free slot, ok is false if the status could not be fetched
+func (c *Client) slotWait(ctx context.Context) (wait time.Duration, ok bool) {
+	if c.StatusURL == "" {
+		return 0, false
+	}
+
+	status, err := c.StatusContext(ctx)
+
+	if err != nil {
+		return 0, false
+	}
+
+	return status.NextSlot(), true
+}
+
+// Sends a request with the client's user agent and timeout applied, the timeout covers reading
+// the response body which must be closed by the caller.
+func (c *Client) do(ctx context.Context, method string, url string, body io.Reader) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{resp.Body, cancel}
+	return resp, nil
+}
+
+// Releases the request's timeout once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
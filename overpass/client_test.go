@@ -0,0 +1,100 @@
+package overpass
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, interpreter http.HandlerFunc) (*httptest.Server, *Client) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Connected as: 1\nCurrent time: 2022-11-15T12:23:09Z\nRate limit: 2\n2 slots available now.\nCurrently running queries (pid, space limit, time limit, start time):\n")
+	})
+	mux.HandleFunc("/api/interpreter", interpreter)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, NewClient(server.URL+"/api/interpreter", server.URL+"/api/status")
+}
+
+func TestClientQuery(t *testing.T) {
+	var gotQuery, gotUserAgent string
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotQuery = string(body)
+		gotUserAgent = r.UserAgent()
+		io.WriteString(w, `{"elements":[]}`)
+	})
+	client.UserAgent = "osmdata-test"
+
+	stack := NewStackStatement(
+		NewSettingsStatement(*NewSetting(Out, JSON)),
+		NewElementStatement(Node, []TagFilter{*NewTagFilter(Equals, "highway", "traffic_signals")}),
+		NewOutStatement(Body),
+	).WithClient(client)
+
+	resp, err := stack.Execute()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp != `{"elements":[]}` {
+		t.Errorf("unexpected response: %s", resp)
+	}
+
+	if gotQuery != stack.GetCompiled() {
+		t.Errorf("server received %q, expected %q", gotQuery, stack.GetCompiled())
+	}
+
+	if gotUserAgent != "osmdata-test" {
+		t.Errorf("server received user agent %q", gotUserAgent)
+	}
+}
+
+func TestClientQueryHTTPError(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, "bad query")
+	})
+
+	resp, err := client.QueryBytes("node(1);out;")
+
+	if err == nil {
+		t.Fatalf("expected error for status code 400")
+	}
+
+	if len(resp) != 0 {
+		t.Errorf("expected empty response, got %s", resp)
+	}
+}
+
+func TestClientQueryContextCancel(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.QueryContext(ctx, "node(1);out;")
+
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("cancellation did not abort the request")
+	}
+}
@@ -0,0 +1,395 @@
+package overpass
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVField is a column of [out:csv] output, either one of the special fields below or a tag key,
+// e.g. CSVField("name")
+type CSVField string
+
+const (
+	CSVID             CSVField = "::id"              // OSM object id
+	CSVType           CSVField = "::type"            // OSM object type: node, way, relation
+	CSVOType          CSVField = "::otype"           // OSM object as numeric value
+	CSVLat            CSVField = "::lat"             // Latitude (available for nodes, or in out center mode)
+	CSVLon            CSVField = "::lon"             // Longitude (available for nodes, or in out center mode)
+	CSVVersion        CSVField = "::version"         // OSM object's version number, requires out meta
+	CSVTimestamp      CSVField = "::timestamp"       // Last changed timestamp of an OSM object, requires out meta
+	CSVChangeset      CSVField = "::changeset"       // Changeset in which the object was changed, requires out meta
+	CSVUID            CSVField = "::uid"             // OSM User id, requires out meta
+	CSVUser           CSVField = "::user"            // OSM User name, requires out meta
+	CSVCount          CSVField = "::count"           // Total number of objects (nodes, ways, relations and areas) in inputset, requires out count
+	CSVCountNodes     CSVField = "::count:nodes"     // Number of nodes in inputset, requires out count
+	CSVCountWays      CSVField = "::count:ways"      // Number of ways in inputset, requires out count
+	CSVCountRelations CSVField = "::count:relations" // Number of relations in inputset, requires out count
+	CSVCountAreas     CSVField = "::count:areas"     // Number of areas in inputset, requires out count
+)
+
+// Returns true for special fields, i.e. those starting with "::"
+func (f CSVField) IsSpecial() bool {
+	return strings.HasPrefix(string(f), "::")
+}
+
+// Returns the column name Overpass prints in the header line, special fields are printed with an
+// "@" prefix instead of "::", e.g. ::id becomes @id
+func (f CSVField) HeaderName() string {
+	if f.IsSpecial() {
+		return "@" + strings.TrimPrefix(string(f), "::")
+	}
+	return string(f)
+}
+
+// CSVOptions specifies the columns, header line and separator of [out:csv] output
+type CSVOptions struct {
+	Fields    []CSVField
+	Header    bool   // Whether the first line lists the field names, default true
+	Separator string // Column separator, default tab
+}
+
+// Returns a pointer to new CSVOptions with the given fields, a header line and tab separator
+func NewCSVOptions(fields ...CSVField) *CSVOptions {
+	o := new(CSVOptions)
+	o.Fields = fields
+	o.Header = true
+	o.Separator = "\t"
+	return o
+}
+
+// Returns the options in Overpass syntax, e.g. (::id,::type,"name";true;",")
+func (o *CSVOptions) String() string {
+	fields := make([]string, len(o.Fields))
+	for i, f := range o.Fields {
+		if f.IsSpecial() {
+			fields[i] = string(f)
+		} else {
+			fields[i] = strconv.Quote(string(f))
+		}
+	}
+
+	c := fmt.Sprintf("(%s;%t", strings.Join(fields, ","), o.Header)
+
+	if o.separator() != "\t" {
+		c += ";" + strconv.Quote(o.separator())
+	}
+
+	return c + ")"
+}
+
+func (o *CSVOptions) separator() string {
+	if o.Separator == "" {
+		return "\t"
+	}
+	return o.Separator
+}
+
+func (o *CSVOptions) Validate() error {
+	if len(o.Fields) == 0 {
+		return fmt.Errorf("CSV output format requires at least one field")
+	}
+
+	for _, f := range o.Fields {
+		if f == "" {
+			return fmt.Errorf("CSV output format fields cannot be empty")
+		}
+	}
+
+	if len([]rune(o.separator())) != 1 {
+		return fmt.Errorf("CSV separator must be a single character, not %q", o.Separator)
+	}
+
+	return nil
+}
+
+// Parses options in Overpass syntax, e.g. (::id,::type,"name";true;","), as accepted by NewSetting
+func ParseCSVOptions(options string) (*CSVOptions, error) {
+	s := strings.TrimSpace(options)
+
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("CSV options %q must be enclosed in parentheses", options)
+	}
+
+	parts, err := splitCSVOptions(s[1:len(s)-1], ';')
+
+	if err != nil || len(parts) > 3 {
+		return nil, fmt.Errorf("malformed CSV options %q", options)
+	}
+
+	o := NewCSVOptions()
+	fields, err := splitCSVOptions(parts[0], ',')
+
+	if err != nil {
+		return nil, fmt.Errorf("malformed CSV options %q", options)
+	}
+
+	for _, f := range fields {
+		name, err := unquoteCSVField(f)
+		if err != nil {
+			return nil, fmt.Errorf("malformed CSV field %s in %q", f, options)
+		}
+		o.Fields = append(o.Fields, CSVField(name))
+	}
+
+	if len(parts) > 1 {
+		if o.Header, err = strconv.ParseBool(strings.TrimSpace(parts[1])); err != nil {
+			return nil, fmt.Errorf("malformed CSV header flag %q in %q", parts[1], options)
+		}
+	}
+
+	if len(parts) > 2 {
+		if o.Separator, err = unquoteCSVOption(parts[2]); err != nil {
+			return nil, fmt.Errorf("malformed CSV separator %q in %q", parts[2], options)
+		}
+	}
+
+	return o, o.Validate()
+}
+
+// Splits s on sep outside of double quoted strings
+func splitCSVOptions(s string, sep rune) ([]string, error) {
+	var parts []string
+	var c strings.Builder
+	quoted, escaped := false, false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == sep:
+			parts = append(parts, c.String())
+			c.Reset()
+			continue
+		}
+		c.WriteRune(r)
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated string in %q", s)
+	}
+
+	return append(parts, c.String()), nil
+}
+
+// Unquotes a field name, including the ::"id" form of special fields, so it matches the CSVField
+// constants. "::id" is unquoted as any other quoted field.
+func unquoteCSVField(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "::\"") {
+		name, err := strconv.Unquote(s[2:])
+		return "::" + name, err
+	}
+	return unquoteCSVOption(s)
+}
+
+func unquoteCSVOption(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "\"") {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+// Returns a pointer to a new Setting requesting [out:csv] output with the given options, the
+// options are kept on the setting so responses can be decoded against them
+func NewCSVSetting(options *CSVOptions) *Setting {
+	s := NewSetting(Out, CSV, options.String())
+	s.csv = options
+
+	if err := options.Validate(); err != nil {
+		s.err = err
+	}
+
+	return s
+}
+
+// CSVRecord is a row of [out:csv] output keyed by field
+type CSVRecord map[CSVField]string
+
+// Returns the ::id column
+func (r CSVRecord) ID() (int64, error) {
+	return strconv.ParseInt(r[CSVID], 10, 64)
+}
+
+// Returns the ::type column
+func (r CSVRecord) Type() ElementType {
+	return ElementType(r[CSVType])
+}
+
+// Returns the ::lat column
+func (r CSVRecord) Lat() (float64, error) {
+	return strconv.ParseFloat(r[CSVLat], 64)
+}
+
+// Returns the ::lon column
+func (r CSVRecord) Lon() (float64, error) {
+	return strconv.ParseFloat(r[CSVLon], 64)
+}
+
+// Returns the value of a tag column, empty if the element does not have the tag
+func (r CSVRecord) Tag(key string) string {
+	return r[CSVField(key)]
+}
+
+// CSVDecoder reads the rows of [out:csv] output one at a time, checking them against the options
+// the query was built with
+type CSVDecoder struct {
+	options *CSVOptions
+	scanner *bufio.Scanner
+	line    int
+	err     error
+}
+
+// Returns a pointer to a new CSVDecoder reading output produced with the given options, invalid
+// options are returned by the first call to Next
+func NewCSVDecoder(r io.Reader, options *CSVOptions) *CSVDecoder {
+	d := new(CSVDecoder)
+	d.options = options
+	d.scanner = bufio.NewScanner(r)
+	d.scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	d.err = options.Validate()
+	return d
+}
+
+// Returns the next row, io.EOF once the output is exhausted. The header line, if the options
+// specify one, is checked against the fields and skipped. Overpass does not quote values, so a row
+// whose tag value contains the separator has more columns than fields and is returned as an error
+// rather than a misaligned record.
+func (d *CSVDecoder) Next() (CSVRecord, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	sep := d.options.separator()
+
+	for d.scanner.Scan() {
+		d.line++
+		line := strings.TrimSuffix(d.scanner.Text(), "\r")
+		values := strings.Split(line, sep)
+
+		if d.line == 1 && d.options.Header {
+			if err := d.checkHeader(values); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if line == "" && len(d.options.Fields) > 1 {
+			continue
+		}
+
+		if len(values) != len(d.options.Fields) {
+			return nil, fmt.Errorf("CSV line %d has %d columns, expected %d, values containing the separator %q cannot be decoded", d.line, len(values), len(d.options.Fields), sep)
+		}
+
+		record := make(CSVRecord, len(values))
+		for i, f := range d.options.Fields {
+			record[f] = values[i]
+		}
+
+		return record, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Overpass CSV response: %w", err)
+	}
+
+	if d.line == 0 && d.options.Header {
+		return nil, fmt.Errorf("CSV output is missing its header line")
+	}
+
+	return nil, io.EOF
+}
+
+func (d *CSVDecoder) checkHeader(values []string) error {
+	if len(values) != len(d.options.Fields) {
+		return fmt.Errorf("CSV header has %d columns, expected %d", len(values), len(d.options.Fields))
+	}
+
+	for i, f := range d.options.Fields {
+		if values[i] != f.HeaderName() {
+			return fmt.Errorf("CSV header column %d is %q, expected %q", i+1, values[i], f.HeaderName())
+		}
+	}
+
+	return nil
+}
+
+// Decodes [out:csv] output produced with the given options
+func DecodeCSV(r io.Reader, options *CSVOptions) ([]CSVRecord, error) {
+	d := NewCSVDecoder(r, options)
+	var records []CSVRecord
+
+	for {
+		record, err := d.Next()
+
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+}
+
+// Executes the query and decodes the rows of its [out:csv] output, checked against the CSV options
+// of the query's settings
+// Specify maxAttempts to retry when rate limited, defaults to 1
+func (s *StackStatement) ExecuteCSV(maxAttempts ...int) ([]CSVRecord, error) {
+	return s.ExecuteCSVContext(context.Background(), maxAttempts...)
+}
+
+// Same as ExecuteCSV, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteCSVContext(ctx context.Context, maxAttempts ...int) ([]CSVRecord, error) {
+	options, err := s.GetCSVOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	stream, err := s.client().QueryStreamContext(ctx, s.GetCompiled(), maxAttempts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer stream.Close()
+	records, err := DecodeCSV(stream, options)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return records, err
+}
+
+// Returns the CSV options of the stack's output setting, an error if the stack does not request
+// [out:csv] output
+func (s *StackStatement) GetCSVOptions() (*CSVOptions, error) {
+	setting := s.outSetting()
+
+	if setting == nil || OutType(setting.Value) != CSV {
+		return nil, fmt.Errorf("query does not request CSV output, it outputs %s", s.GetOutType())
+	}
+
+	if setting.csv != nil {
+		return setting.csv, nil
+	}
+
+	return ParseCSVOptions(setting.Options)
+}
@@ -0,0 +1,114 @@
+package overpass
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCSVOptions(t *testing.T) {
+	options := NewCSVOptions(CSVID, CSVType, CSVLat, CSVLon, "addr:street")
+	options.Separator = ","
+
+	if options.String() != `(::id,::type,::lat,::lon,"addr:street";true;",")` {
+		t.Errorf("unexpected options %s", options)
+	}
+
+	parsed, err := ParseCSVOptions(options.String())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if parsed.String() != options.String() {
+		t.Errorf("round trip produced %s, expected %s", parsed, options)
+	}
+
+	// Overpass also accepts the special fields quoted
+	quoted, err := ParseCSVOptions(`(::"id","::lat",::lon,"name")`)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := NewCSVOptions(CSVID, CSVLat, CSVLon, "name"); quoted.String() != expected.String() {
+		t.Errorf("quoted special fields parsed to %s, expected %s", quoted, expected)
+	}
+
+	if NewCSVOptions(CSVID).String() != "(::id;true)" {
+		t.Errorf("unexpected default options %s", NewCSVOptions(CSVID))
+	}
+
+	if err := NewCSVSetting(NewCSVOptions()).Validate(); err == nil {
+		t.Errorf("expected an error for CSV options without fields")
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	options := NewCSVOptions(CSVID, CSVType, CSVLat, CSVLon, "name")
+
+	records, err := DecodeCSV(strings.NewReader("@id\t@type\t@lat\t@lon\tname\n1\tnode\t-37.74\t144.93\tFlinders Street\n2\tnode\t-37.75\t144.94\t\n"), options)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	id, _ := records[0].ID()
+	lat, _ := records[0].Lat()
+
+	if id != 1 || lat != -37.74 || records[0].Type() != Node || records[0].Tag("name") != "Flinders Street" {
+		t.Errorf("unexpected record %v", records[0])
+	}
+
+	if _, err := DecodeCSV(strings.NewReader("@id\t@type\n1\tnode\n"), options); err == nil {
+		t.Errorf("expected an error for a header that does not match the options")
+	}
+
+	if records, err := DecodeCSV(strings.NewReader("@id\t@type\t@lat\t@lon\tname\n1\tnode\t-37.74\t144.93\tFlinders\tStreet\n"), options); err == nil {
+		t.Errorf("expected an error for a value containing the separator, got %v", records)
+	}
+
+	if _, err := NewCSVDecoder(strings.NewReader("1\n"), NewCSVOptions()).Next(); err == nil {
+		t.Errorf("expected an error for options without fields")
+	}
+
+	options.Header = false
+
+	if _, err := DecodeCSV(strings.NewReader("1\tnode\n"), options); err == nil {
+		t.Errorf("expected an error for rows that do not match the options")
+	}
+}
+
+func TestExecuteCSV(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "@id,@type\n1,node\n")
+	})
+
+	options := NewCSVOptions(CSVID, CSVType)
+	options.Separator = ","
+
+	stack := NewStackStatement(
+		NewSettingsStatement(*NewCSVSetting(options)),
+		NewElementStatement(Node, []TagFilter{}, NewElementFilter(IDFilter, 1)),
+		NewOutStatement(Body),
+	).WithClient(client)
+
+	if !strings.HasPrefix(stack.GetCompiled(), `[out:csv(::id,::type;true;",")];`) {
+		t.Errorf("unexpected query %s", stack.GetCompiled())
+	}
+
+	records, err := stack.ExecuteCSV()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 1 || records[0][CSVType] != "node" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
@@ -0,0 +1,416 @@
+package overpass
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ElementHandler is called for every element decoded from a streamed response, returning an error
+// stops the stream and is returned to the caller
+type ElementHandler func(e Element) error
+
+// ElementDecoder reads the elements of an Overpass JSON or XML response one at a time, so
+// responses can be processed without holding them in memory.
+type ElementDecoder struct {
+	outType    OutType
+	jsonDec    *json.Decoder
+	xmlDec     *xml.Decoder
+	inElements bool
+	header     Response
+	done       bool
+}
+
+// Returns a pointer to a new ElementDecoder reading a response of the given output format, only
+// JSON and XML are supported.
+func NewElementDecoder(r io.Reader, outType OutType) *ElementDecoder {
+	d := new(ElementDecoder)
+	d.outType = outType
+
+	switch outType {
+	case JSON:
+		d.jsonDec = json.NewDecoder(r)
+	case XML:
+		d.xmlDec = xml.NewDecoder(r)
+	}
+
+	return d
+}
+
+// Returns the next element, io.EOF once the response is exhausted. Runtime errors reported by the
+// server in a remark are returned as typed errors (see QueryBytes) in place of io.EOF.
+func (d *ElementDecoder) Next() (Element, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+
+	var e Element
+	var err error
+
+	switch d.outType {
+	case JSON:
+		e, err = d.nextJSON()
+	case XML:
+		e, err = d.nextXML()
+	default:
+		err = fmt.Errorf("cannot stream Overpass %s output, only json and xml are supported", d.outType)
+	}
+
+	if err != nil {
+		d.done = true
+
+		if err == io.EOF {
+			if remarkErr := remarkError(d.header.Remark); remarkErr != nil {
+				return nil, remarkErr
+			}
+		}
+	}
+
+	return e, err
+}
+
+// Returns the remark reported by the server, available once Next has returned io.EOF
+func (d *ElementDecoder) Remark() string {
+	return d.header.Remark
+}
+
+// Returns the document level fields of the response (version, generator, osm3s, bounds and remark)
+// without its elements. Fields preceding the elements are available once Next has returned the
+// first element, the remark once Next has returned io.EOF.
+func (d *ElementDecoder) Header() *Response {
+	header := d.header
+	return &header
+}
+
+// Calls handler for every remaining element, returns nil once the response is exhausted
+func (d *ElementDecoder) Each(handler ElementHandler) error {
+	for {
+		e, err := d.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := handler(e); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *ElementDecoder) nextJSON() (Element, error) {
+	for {
+		if d.inElements {
+			if d.jsonDec.More() {
+				var raw json.RawMessage
+
+				if err := d.jsonDec.Decode(&raw); err != nil {
+					return nil, fmt.Errorf("failed to decode Overpass JSON response: %w", err)
+				}
+
+				return decodeJSONElement(raw)
+			}
+
+			// Closing bracket of the elements array
+			if _, err := d.jsonDec.Token(); err != nil {
+				return nil, fmt.Errorf("failed to decode Overpass JSON response: %w", err)
+			}
+
+			d.inElements = false
+		}
+
+		t, err := d.jsonDec.Token()
+
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass JSON response: %w", err)
+		}
+
+		key, ok := t.(string)
+
+		if !ok {
+			// Opening and closing braces of the document
+			continue
+		}
+
+		switch key {
+		case "elements":
+			if t, err := d.jsonDec.Token(); err != nil || t != json.Delim('[') {
+				return nil, fmt.Errorf("failed to decode Overpass JSON response: expected elements array")
+			}
+			d.inElements = true
+
+		case "version":
+			err = d.jsonDec.Decode(&d.header.Version)
+
+		case "generator":
+			err = d.jsonDec.Decode(&d.header.Generator)
+
+		case "osm3s":
+			err = d.jsonDec.Decode(&d.header.OSM3S)
+
+		case "remark":
+			err = d.jsonDec.Decode(&d.header.Remark)
+
+		default:
+			var skip json.RawMessage
+			err = d.jsonDec.Decode(&skip)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass JSON response: %w", err)
+		}
+	}
+}
+
+func (d *ElementDecoder) nextXML() (Element, error) {
+	for {
+		t, err := d.xmlDec.Token()
+
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass XML response: %w", err)
+		}
+
+		start, ok := t.(xml.StartElement)
+
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "osm":
+			for _, attr := range start.Attr {
+				switch attr.Name.Local {
+				case "version":
+					d.header.Version, err = strconv.ParseFloat(attr.Value, 64)
+				case "generator":
+					d.header.Generator = attr.Value
+				}
+			}
+
+		case "note":
+			var note string
+			err = d.xmlDec.DecodeElement(&note, &start)
+			d.header.OSM3S.Copyright = strings.TrimSpace(note)
+
+		case "meta":
+			var meta struct {
+				OSMBase   string `xml:"osm_base,attr"`
+				AreasBase string `xml:"areas,attr"`
+			}
+			err = d.xmlDec.DecodeElement(&meta, &start)
+			d.header.OSM3S.TimestampOSMBase = meta.OSMBase
+			d.header.OSM3S.TimestampAreasBase = meta.AreasBase
+
+		case "bounds":
+			d.header.Bounds = new(Bounds)
+			err = d.xmlDec.DecodeElement(d.header.Bounds, &start)
+
+		case "remark":
+			var remark string
+			err = d.xmlDec.DecodeElement(&remark, &start)
+			d.header.Remark = strings.TrimSpace(remark)
+
+		default:
+			var x xmlElement
+			if err := d.xmlDec.DecodeElement(&x, &start); err != nil {
+				return nil, fmt.Errorf("failed to decode Overpass XML %s element: %w", start.Name.Local, err)
+			}
+			return x.toElement()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass XML response: %w", err)
+		}
+	}
+}
+
+// An element of an Overpass XML response, e.g.
+//
+//	<way id="10" version="3" timestamp="2020-01-01T00:00:00Z" changeset="42" user="mapper" uid="7">
+//	  <bounds minlat="-37.75" minlon="144.93" maxlat="-37.74" maxlon="144.94"/>
+//	  <nd ref="1" lat="-37.74" lon="144.93"/>
+//	  <tag k="highway" v="residential"/>
+//	</way>
+type xmlElement struct {
+	XMLName   xml.Name
+	ID        int64       `xml:"id,attr"`
+	Lat       float64     `xml:"lat,attr"`
+	Lon       float64     `xml:"lon,attr"`
+	Version   int         `xml:"version,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Changeset int64       `xml:"changeset,attr"`
+	User      string      `xml:"user,attr"`
+	UID       int64       `xml:"uid,attr"`
+	Bounds    *Bounds     `xml:"bounds"`
+	Center    *xmlLatLon  `xml:"center"`
+	Nds       []xmlNd     `xml:"nd"`
+	Members   []xmlMember `xml:"member"`
+	Tags      []xmlTag    `xml:"tag"`
+}
+
+type xmlLatLon struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type xmlNd struct {
+	Ref int64   `xml:"ref,attr"`
+	Lat *string `xml:"lat,attr"`
+	Lon *string `xml:"lon,attr"`
+}
+
+type xmlMember struct {
+	Type ElementType `xml:"type,attr"`
+	Ref  int64       `xml:"ref,attr"`
+	Role string      `xml:"role,attr"`
+	Lat  float64     `xml:"lat,attr"`
+	Lon  float64     `xml:"lon,attr"`
+	Nds  []xmlNd     `xml:"nd"`
+}
+
+type xmlTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+func (b *Bounds) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var x struct {
+		MinLat float64 `xml:"minlat,attr"`
+		MinLon float64 `xml:"minlon,attr"`
+		MaxLat float64 `xml:"maxlat,attr"`
+		MaxLon float64 `xml:"maxlon,attr"`
+	}
+
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+
+	*b = Bounds(x)
+	return nil
+}
+
+// Converts the XML representation into the same typed element the JSON decoder produces
+func (x *xmlElement) toElement() (Element, error) {
+	meta := ElementMeta{
+		Type:      ElementType(x.XMLName.Local),
+		ID:        x.ID,
+		Version:   x.Version,
+		Changeset: x.Changeset,
+		User:      x.User,
+		UID:       x.UID,
+	}
+
+	if len(x.Tags) > 0 {
+		meta.Tags = make(map[string]string, len(x.Tags))
+		for _, t := range x.Tags {
+			meta.Tags[t.Key] = t.Value
+		}
+	}
+
+	if x.Timestamp != "" {
+		timestamp, err := time.Parse(time.RFC3339, x.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass XML %s %d: %w", meta.Type, meta.ID, err)
+		}
+		meta.Timestamp = timestamp
+	}
+
+	var center *LatLon
+	if x.Center != nil {
+		center = &LatLon{x.Center.Lat, x.Center.Lon}
+	}
+
+	switch meta.Type {
+	case Node:
+		return &NodeElement{ElementMeta: meta, Lat: x.Lat, Lon: x.Lon}, nil
+
+	case Way:
+		w := &WayElement{ElementMeta: meta, Bounds: x.Bounds, Center: center}
+		for _, nd := range x.Nds {
+			if nd.Ref != 0 {
+				w.Nodes = append(w.Nodes, nd.Ref)
+			}
+		}
+		geometry, err := xmlGeometry(x.Nds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Overpass XML way %d: %w", meta.ID, err)
+		}
+		w.Geometry = geometry
+		return w, nil
+
+	case Relation:
+		r := &RelationElement{ElementMeta: meta, Bounds: x.Bounds, Center: center}
+		for _, m := range x.Members {
+			geometry, err := xmlGeometry(m.Nds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode Overpass XML relation %d: %w", meta.ID, err)
+			}
+			r.Members = append(r.Members, Member{Type: m.Type, Ref: m.Ref, Role: m.Role, Lat: m.Lat, Lon: m.Lon, Geometry: geometry})
+		}
+		return r, nil
+
+	case Area:
+		return &AreaElement{ElementMeta: meta}, nil
+
+	case countElementType:
+		c := &CountElement{ElementMeta: meta}
+		c.parseCounts()
+		return c, nil
+
+	default:
+		return &DerivedElement{ElementMeta: meta}, nil
+	}
+}
+
+// Returns the coordinates of nd children output by geom, nil if they carry none. Nodes outside the
+// global bounding box carry no coordinates and are left at zero, as they are null in JSON output.
+func xmlGeometry(nds []xmlNd) ([]LatLon, error) {
+	hasGeometry := false
+	for _, nd := range nds {
+		if nd.Lat != nil {
+			hasGeometry = true
+			break
+		}
+	}
+
+	if !hasGeometry {
+		return nil, nil
+	}
+
+	geometry := make([]LatLon, len(nds))
+
+	for i, nd := range nds {
+		if nd.Lat == nil || nd.Lon == nil {
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(*nd.Lat, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		lon, err := strconv.ParseFloat(*nd.Lon, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		geometry[i] = LatLon{lat, lon}
+	}
+
+	return geometry, nil
+}
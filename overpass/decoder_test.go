@@ -0,0 +1,185 @@
+package overpass
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testXMLResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6" generator="Overpass API 0.7.59 e21c39fe">
+<note>The data included in this document is from www.openstreetmap.org. The data is made available under ODbL.</note>
+<meta osm_base="2022-11-15T12:23:09Z"/>
+  <bounds minlat="-37.76" minlon="144.92" maxlat="-37.73" maxlon="144.95"/>
+  <node id="1" lat="-37.74" lon="144.93" version="3" timestamp="2020-01-01T00:00:00Z" changeset="42" user="mapper" uid="7">
+    <tag k="highway" v="traffic_signals"/>
+  </node>
+  <node id="2" lat="-37.75" lon="144.94"/>
+  <way id="10">
+    <bounds minlat="-37.75" minlon="144.93" maxlat="-37.74" maxlon="144.94"/>
+    <nd ref="1" lat="-37.74" lon="144.93"/>
+    <nd ref="2"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="11">
+    <center lat="-37.745" lon="144.935"/>
+  </way>
+  <relation id="100">
+    <member type="way" ref="10" role="from">
+      <nd lat="-37.74" lon="144.93"/>
+    </member>
+    <member type="node" ref="1" role="via" lat="-37.74" lon="144.93"/>
+    <tag k="type" v="restriction"/>
+  </relation>
+  <area id="3600000100">
+    <tag k="name" v="Melbourne"/>
+  </area>
+  <count id="0">
+    <tag k="nodes" v="2"/>
+    <tag k="ways" v="2"/>
+    <tag k="relations" v="1"/>
+    <tag k="areas" v="1"/>
+    <tag k="total" v="6"/>
+  </count>
+</osm>
+`
+
+func TestElementDecoder(t *testing.T) {
+	var testCases = []struct {
+		outType OutType
+		body    string
+	}{
+		{JSON, testJSONResponse},
+		{XML, testXMLResponse},
+	}
+
+	for _, test := range testCases {
+		var elements []Element
+
+		err := NewElementDecoder(strings.NewReader(test.body), test.outType).Each(func(e Element) error {
+			elements = append(elements, e)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.outType, err)
+			continue
+		}
+
+		if len(elements) != 7 {
+			t.Errorf("%v: expected 7 elements, got %d", test.outType, len(elements))
+			continue
+		}
+
+		node := elements[0].(*NodeElement)
+		if node.ID != 1 || node.Lat != -37.74 || node.Tags["highway"] != "traffic_signals" || node.User != "mapper" || node.Timestamp.Year() != 2020 {
+			t.Errorf("%v: unexpected node %+v", test.outType, node)
+		}
+
+		way := elements[2].(*WayElement)
+		if len(way.Nodes) != 2 || way.Nodes[1] != 2 || way.Bounds == nil || way.Bounds.MaxLon != 144.94 || way.Geometry[0].Lat != -37.74 {
+			t.Errorf("%v: unexpected way %+v", test.outType, way)
+		}
+
+		if center := elements[3].(*WayElement).Center; center == nil || center.Lon != 144.935 {
+			t.Errorf("%v: unexpected center %+v", test.outType, center)
+		}
+
+		relation := elements[4].(*RelationElement)
+		if len(relation.Members) != 2 || relation.Members[0].Role != "from" || len(relation.Members[0].Geometry) != 1 || relation.Members[1].Lon != 144.93 {
+			t.Errorf("%v: unexpected relation %+v", test.outType, relation)
+		}
+
+		if count := elements[6].(*CountElement); count.Total != 6 {
+			t.Errorf("%v: unexpected count %+v", test.outType, count)
+		}
+	}
+}
+
+func TestElementDecoderRemark(t *testing.T) {
+	var testCases = []struct {
+		outType OutType
+		body    string
+	}{
+		{JSON, `{"elements":[{"type":"node","id":1}],"remark":"runtime error: Query timed out in \"query\" at line 1 after 2 seconds."}`},
+		{XML, `<osm><node id="1"/><remark> runtime error: Query timed out in "query" at line 1 after 2 seconds. </remark></osm>`},
+	}
+
+	for _, test := range testCases {
+		count := 0
+		err := NewElementDecoder(strings.NewReader(test.body), test.outType).Each(func(e Element) error {
+			count++
+			return nil
+		})
+
+		var timeout *QueryTimeoutError
+		if !errors.As(err, &timeout) || count != 1 {
+			t.Errorf("%v: expected 1 element and a timeout, got %d and %v", test.outType, count, err)
+		}
+	}
+}
+
+func TestExecuteStreamAndExport(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testXMLResponse)
+	})
+
+	stack := NewStackStatement(
+		NewElementStatement(Node, []TagFilter{}, NewElementFilter(IDFilter, 1)),
+		NewOutStatement(Body),
+	).WithClient(client)
+
+	filename := filepath.Join(t.TempDir(), "test.osm")
+	ways := 0
+
+	err := stack.ExecuteStreamAndExport(filename, func(e Element) error {
+		if _, ok := e.(*WayElement); ok {
+			ways++
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ways != 2 {
+		t.Errorf("expected 2 ways, got %d", ways)
+	}
+
+	exported, err := os.ReadFile(filename)
+
+	if err != nil || string(exported) != testXMLResponse {
+		t.Errorf("exported file does not match the response: %v", err)
+	}
+}
+
+func TestExportFailures(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testXMLResponse[:len(testXMLResponse)/2])
+	})
+
+	stack := NewStackStatement(
+		NewElementStatement(Node, []TagFilter{}, NewElementFilter(IDFilter, 1)),
+		NewOutStatement(Body),
+	).WithClient(client)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.osm")
+
+	if err := stack.ExecuteStreamAndExport(filename, func(e Element) error { return nil }); err == nil {
+		t.Errorf("expected error for a truncated response")
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected no export file to be left behind, got %d files", len(files))
+	}
+
+	if _, err := stack.ExecuteAndExport(filepath.Join(dir, "missing", "test.osm")); err == nil {
+		t.Errorf("expected ExecuteAndExport to report the write error")
+	}
+}
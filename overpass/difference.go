@@ -6,7 +6,17 @@ import (
 	"strings"
 )
 
+// Returns a pointer to a new DifferenceStatement, unions and differences cannot be nested inside a
+// difference, such a difference compiles to nothing and is reported by Validate. Use
+// NewDifferenceStatementE to get the error from construction.
 func NewDifferenceStatement(resultSetName string, firstStatement Statement, secondStatement Statement) *DifferenceStatement {
+	s, _ := NewDifferenceStatementE(resultSetName, firstStatement, secondStatement)
+	return s
+}
+
+// Same as NewDifferenceStatement but also returns an error if a statement is missing or is a union
+// or difference
+func NewDifferenceStatementE(resultSetName string, firstStatement Statement, secondStatement Statement) (*DifferenceStatement, error) {
 	s := new(DifferenceStatement)
 	if resultSetName != "" {
 		s.SetName = resultSetName
@@ -14,22 +24,34 @@ func NewDifferenceStatement(resultSetName string, firstStatement Statement, seco
 	s.FirstStatement = firstStatement
 	s.SecondStatement = secondStatement
 	s.compiled = s.compile()
+	return s, s.err
+}
+
+// Same as NewDifferenceStatement but panics if the difference is invalid
+func MustNewDifferenceStatement(resultSetName string, firstStatement Statement, secondStatement Statement) *DifferenceStatement {
+	s := NewDifferenceStatement(resultSetName, firstStatement, secondStatement)
+
+	if err := s.Validate(); err != nil {
+		panic(err)
+	}
+
 	return s
 }
 
+// Compiles the difference and records missing or block statements in s.err
 func (s *DifferenceStatement) compile() string {
-	_, isUnionFirst := s.FirstStatement.(*UnionStatement)
-	_, isDiffFirst := s.FirstStatement.(*DifferenceStatement)
+	var errs []error
 
-	if isUnionFirst || isDiffFirst {
-		panic(fmt.Errorf("%s cannot wrap %s", reflect.TypeOf(s), reflect.TypeOf(s.FirstStatement)))
+	for _, _s := range []Statement{s.FirstStatement, s.SecondStatement} {
+		if _s == nil {
+			errs = append(errs, fmt.Errorf("%s requires two statements", reflect.TypeOf(s)))
+		} else if isBlockStatement(_s) {
+			errs = append(errs, fmt.Errorf("%s cannot wrap %s", reflect.TypeOf(s), reflect.TypeOf(_s)))
+		}
 	}
 
-	_, isUnionSecond := s.SecondStatement.(*UnionStatement)
-	_, isDiffSecond := s.SecondStatement.(*DifferenceStatement)
-
-	if isUnionSecond || isDiffSecond {
-		panic(fmt.Errorf("%s cannot wrap %s", reflect.TypeOf(s), reflect.TypeOf(s.FirstStatement)))
+	if s.err = newValidationError(errs...); s.err != nil {
+		return ""
 	}
 
 	var c strings.Builder
@@ -53,3 +75,15 @@ func (s *DifferenceStatement) GetCompiled() string {
 func (s *DifferenceStatement) GetSetName() string {
 	return s.SetName
 }
+
+func (s *DifferenceStatement) Validate() error {
+	errs := []error{s.err}
+
+	for _, _s := range []Statement{s.FirstStatement, s.SecondStatement} {
+		if _s != nil && !isBlockStatement(_s) {
+			errs = append(errs, _s.Validate())
+		}
+	}
+
+	return newValidationError(errs...)
+}
@@ -49,3 +49,10 @@ func (s *ElementStatement) GetCompiled() string {
 func (s *ElementStatement) GetSetName() string {
 	return ""
 }
+
+func (s *ElementStatement) Validate() error {
+	if s.ElementFilter != nil {
+		return newValidationError(s.ElementFilter.Validate())
+	}
+	return nil
+}
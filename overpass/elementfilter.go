@@ -7,17 +7,29 @@ import (
 )
 
 // Returns a pointer to a new ElementFilter, this constructor will also compile the filter string
-// needed for the final query.
+// needed for the final query. Args that do not match the signature are reported by Validate and by
+// the Validate method of any statement holding the filter.
 //
 // The args of this function depends on the elementFilterType, the signature should match:
 //
 //	BoundingBoxFilter: GetBoundingBoxFilterStr(south float64, west float64, north float64, east float64) string
 //	RecurseFilter: GetRecurseFilterStr(elementRecurseType ElementRecurseType) string
 //	IDFilter: GetIDFilterStr(id int, ids ...int) string
+//	Int64IDFilter: GetInt64IDFilterStr(id int64, ids ...int64) string
 //	AroundFilter: GetAroundFilterStr(radius float64, coordinates [][]float64, inputSetName ...string) string
 //	PolygonFilter: GetPolygonFilterStr(coordinates [][]float64) string
-//	AreaFilter: GetAroundFilterStr(radius float64, coordinates []float64, setName ...string) string
+//	AreaFilter: GetAreaFilterStr(id int, ids ...int) string
+//	AreaIDFilter: GetAreaIDFilterStr(areaID int64, areaIDs ...int64) string
+//
+// Use NewElementFilterE to get the error from construction.
 func NewElementFilter(elementFilterType ElementFilterType, args ...interface{}) *ElementFilter {
+	f, _ := NewElementFilterE(elementFilterType, args...)
+	return f
+}
+
+// Same as NewElementFilter but also returns an error if args do not match the signature of the
+// filter type
+func NewElementFilterE(elementFilterType ElementFilterType, args ...interface{}) (*ElementFilter, error) {
 	f := new(ElementFilter)
 	f.ElementFilterType = elementFilterType
 
@@ -29,20 +41,50 @@ func NewElementFilter(elementFilterType ElementFilterType, args ...interface{})
 		getter = GetRecurseFilterStr
 	} else if elementFilterType == IDFilter {
 		getter = GetIDFilterStr
+	} else if elementFilterType == Int64IDFilter {
+		getter = GetInt64IDFilterStr
 	} else if elementFilterType == AroundFilter {
 		getter = GetAroundFilterStr
 	} else if elementFilterType == PolygonFilter {
 		getter = GetPolygonFilterStr
 	} else if elementFilterType == AreaFilter {
-		getter = GetAroundFilterStr
+		getter = GetAreaFilterStr
+	} else if elementFilterType == AreaIDFilter {
+		getter = GetAreaIDFilterStr
+	}
+
+	if getter == nil {
+		f.err = fmt.Errorf("unsupported element filter type '%s'", elementFilterType)
+		return f, f.err
+	}
+
+	result, err := helpers.ExecVaradicFunctionE(getter, args...)
+
+	if err != nil {
+		f.err = fmt.Errorf("invalid args for %s: %w", elementFilterType, err)
+		return f, f.err
 	}
 
-	result := helpers.ExecVaradicFunction(getter, args...)
 	f.FilterStr = result[0].Interface().(string)
 
+	return f, nil
+}
+
+// Same as NewElementFilter but panics if args do not match the signature of the filter type
+func MustNewElementFilter(elementFilterType ElementFilterType, args ...interface{}) *ElementFilter {
+	f := NewElementFilter(elementFilterType, args...)
+
+	if err := f.Validate(); err != nil {
+		panic(err)
+	}
+
 	return f
 }
 
+func (f *ElementFilter) Validate() error {
+	return f.err
+}
+
 func GetBoundingBoxFilterStr(south float64, west float64, north float64, east float64) string {
 	return fmt.Sprintf("%f,%f,%f,%f", south, west, north, east)
 }
@@ -56,6 +98,13 @@ func GetIDFilterStr(id int, ids ...int) string {
 	return fmt.Sprintf("id:%s", helpers.JoinArrInt(idArray))
 }
 
+// Same as GetIDFilterStr but takes int64 ids, e.g. area ids that do not fit in an int on 32-bit
+// platforms
+func GetInt64IDFilterStr(id int64, ids ...int64) string {
+	idArray := append([]int64{id}, ids...)
+	return fmt.Sprintf("id:%s", helpers.JoinArrInt64(idArray))
+}
+
 // Input coordinates for the polygon are in GeoJSON polygon format
 // i.e. [[lon1, lat1], [lon2, lat2], ...]
 // OSM expects string format "lat1,lon1,lat2,lon2, ..."
@@ -81,6 +130,34 @@ func GetAreaFilterStr(id int, ids ...int) string {
 	return fmt.Sprintf("area:%s", helpers.JoinArrInt(idArray))
 }
 
+// Same as GetAreaFilterStr but takes int64 ids, area ids derived from relations (see AreaID) do
+// not fit in an int on 32-bit platforms
+func GetAreaIDFilterStr(areaID int64, areaIDs ...int64) string {
+	idArray := append([]int64{areaID}, areaIDs...)
+	return fmt.Sprintf("area:%s", helpers.JoinArrInt64(idArray))
+}
+
+// Offsets added to relation and way ids to get the id of the area they outline
+const (
+	RelationAreaOffset int64 = 3600000000
+	WayAreaOffset      int64 = 2400000000
+)
+
+// Returns the id of the area outlined by a relation or way, e.g. 3600002402 for relation 2402.
+// Overpass only derives areas from named boundaries, multipolygons and closed ways tagged as areas,
+// see Client.AreaExists.
+func AreaID(elementType ElementType, id int64) (int64, error) {
+	switch elementType {
+	case Relation:
+		return RelationAreaOffset + id, nil
+	case Way:
+		return WayAreaOffset + id, nil
+	case Area:
+		return id, nil
+	}
+	return 0, fmt.Errorf("%s elements do not outline areas", elementType)
+}
+
 // Converts polygon coordinates conforming to GeoJSON [[lon1, lat1], [lon2, lat2], ...]
 // to OSM format [lat1, lon1, lat2, lon2, ...]
 func convertPolygonCooridnates(x [][]float64) []float64 {
@@ -0,0 +1,182 @@
+package overpass
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPError is returned when the interpreter responds with a status code that has no more specific
+// error type
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("overpass engine error (status code %v):\n%s", e.StatusCode, e.Body)
+}
+
+// RateLimitError is returned when the client has no free slot, either because the status endpoint
+// reported none ahead of sending the query or because the interpreter responded 429
+type RateLimitError struct {
+	StatusCode int           // 429, or zero when the query was not sent
+	Wait       time.Duration // Time until the next slot frees up, zero if unknown
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("overpass server is not available for download, next slot available in %v, please wait and try again", e.Wait)
+	}
+	return fmt.Sprintf("overpass rate limit exceeded (status code %v), please wait and try again", e.StatusCode)
+}
+
+// Implements helpers.RetryAfterError so retry policies wait for the next slot
+func (e *RateLimitError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// QueryTimeoutError is returned when the server gave up on the query, either by responding 504
+// because it is too busy or by aborting it after the [timeout:] setting elapsed
+type QueryTimeoutError struct {
+	StatusCode int    // 504, or 200 when the timeout was reported in a remark
+	Remark     string // Runtime error remark, empty for 504 responses
+}
+
+func (e *QueryTimeoutError) Error() string {
+	if e.Remark != "" {
+		return fmt.Sprintf("overpass query timed out: %s", e.Remark)
+	}
+	return fmt.Sprintf("overpass query timed out (status code %v), the server is probably too busy", e.StatusCode)
+}
+
+// OutOfMemoryError is returned when the query exceeded the [maxsize:] setting
+type OutOfMemoryError struct {
+	Remark string
+}
+
+func (e *OutOfMemoryError) Error() string {
+	return fmt.Sprintf("overpass query ran out of memory: %s", e.Remark)
+}
+
+// RuntimeError is returned for runtime error remarks that are neither timeouts nor out of memory
+type RuntimeError struct {
+	Remark string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("overpass runtime error: %s", e.Remark)
+}
+
+// SyntaxError is returned when the interpreter rejects the query, Line and Column locate the first
+// error (Column is zero when the server does not report one) and Messages holds every error
+// reported
+type SyntaxError struct {
+	Line     int
+	Column   int
+	Message  string
+	Messages []string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("overpass query syntax error at line %d: %s", e.Line, e.Message)
+}
+
+var (
+	htmlErrorPattern    = regexp.MustCompile(`(?s)<strong[^>]*>Error</strong>:(.*?)</p>`)
+	syntaxErrorPattern  = regexp.MustCompile(`^\s*line (\d+)(?:, column (\d+))?:\s*(.*?)\s*$`)
+	jsonRemarkPattern   = regexp.MustCompile(`"remark"\s*:\s*("(?:[^"\\]|\\.)*")`)
+	xmlRemarkPattern    = regexp.MustCompile(`(?s)<remark>(.*?)</remark>`)
+	runtimeErrorPattern = []byte("runtime error")
+)
+
+// Classifies an interpreter response into one of the typed errors, returns nil for successful
+// responses
+func responseError(statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusOK:
+		if bytes.Contains(body, runtimeErrorPattern) {
+			return remarkError(findRemark(body))
+		}
+		return nil
+
+	case http.StatusTooManyRequests:
+		return &RateLimitError{StatusCode: statusCode, Body: string(body)}
+
+	case http.StatusGatewayTimeout:
+		return &QueryTimeoutError{StatusCode: statusCode}
+
+	case http.StatusBadRequest:
+		if err := parseSyntaxError(body); err != nil {
+			return err
+		}
+	}
+
+	return &HTTPError{StatusCode: statusCode, Body: string(body)}
+}
+
+// Returns the first runtime error remark in a JSON or XML response body
+func findRemark(body []byte) string {
+	for _, m := range jsonRemarkPattern.FindAllSubmatch(body, -1) {
+		var remark string
+		if json.Unmarshal(m[1], &remark) == nil && strings.Contains(remark, "runtime error") {
+			return strings.TrimSpace(remark)
+		}
+	}
+
+	for _, m := range xmlRemarkPattern.FindAllSubmatch(body, -1) {
+		remark := html.UnescapeString(string(m[1]))
+		if strings.Contains(remark, "runtime error") {
+			return strings.TrimSpace(remark)
+		}
+	}
+
+	return ""
+}
+
+// Returns the typed error for a remark, nil if the remark is not a runtime error
+func remarkError(remark string) error {
+	switch {
+	case !strings.Contains(remark, "runtime error"):
+		return nil
+	case strings.Contains(remark, "timed out"):
+		return &QueryTimeoutError{StatusCode: http.StatusOK, Remark: remark}
+	case strings.Contains(remark, "out of memory"):
+		return &OutOfMemoryError{Remark: remark}
+	default:
+		return &RuntimeError{Remark: remark}
+	}
+}
+
+// Parses the HTML error page returned for invalid queries, e.g.
+//
+//	<p><strong style="color:#FF0000">Error</strong>: line 1: parse error: Unknown type "nod" </p>
+func parseSyntaxError(body []byte) *SyntaxError {
+	var e *SyntaxError
+
+	for _, m := range htmlErrorPattern.FindAllSubmatch(body, -1) {
+		message := strings.TrimSpace(html.UnescapeString(string(m[1])))
+
+		if e == nil {
+			e = new(SyntaxError)
+			e.Message = message
+
+			if l := syntaxErrorPattern.FindStringSubmatch(message); l != nil {
+				e.Line, _ = strconv.Atoi(l[1])
+				e.Column, _ = strconv.Atoi(l[2])
+				e.Message = l[3]
+			}
+		}
+
+		e.Messages = append(e.Messages, message)
+	}
+
+	return e
+}
@@ -0,0 +1,86 @@
+package overpass
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseError(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		statusCode int
+		body       string
+		check      func(err error) bool
+	}{
+		{
+			"ok",
+			http.StatusOK,
+			`{"elements":[{"type":"node","id":1,"tags":{"note":"runtime error in the wild"}}]}`,
+			func(err error) bool { return err == nil },
+		},
+		{
+			"json timeout remark",
+			http.StatusOK,
+			`{"elements":[],"remark":"runtime error: Query timed out in \"query\" at line 1 after 26 seconds."}`,
+			func(err error) bool {
+				var e *QueryTimeoutError
+				return errors.As(err, &e) && e.Remark != ""
+			},
+		},
+		{
+			"xml memory remark",
+			http.StatusOK,
+			"<osm><remark> runtime error: Query run out of memory using about 2048 MB of RAM. </remark></osm>",
+			func(err error) bool {
+				var e *OutOfMemoryError
+				return errors.As(err, &e)
+			},
+		},
+		{
+			"rate limited",
+			http.StatusTooManyRequests,
+			"rate_limited",
+			func(err error) bool {
+				var e *RateLimitError
+				return errors.As(err, &e) && e.StatusCode == http.StatusTooManyRequests
+			},
+		},
+		{
+			"gateway timeout",
+			http.StatusGatewayTimeout,
+			"",
+			func(err error) bool {
+				var e *QueryTimeoutError
+				return errors.As(err, &e) && e.StatusCode == http.StatusGatewayTimeout
+			},
+		},
+		{
+			"syntax error",
+			http.StatusBadRequest,
+			`<p><strong style="color:#FF0000">Error</strong>: line 3: parse error: Unknown type &quot;nod&quot; </p>` +
+				`<p><strong style="color:#FF0000">Error</strong>: line 3: parse error: An empty query is not allowed </p>`,
+			func(err error) bool {
+				var e *SyntaxError
+				return errors.As(err, &e) && e.Line == 3 && e.Message == `parse error: Unknown type "nod"` && len(e.Messages) == 2
+			},
+		},
+		{
+			"other status",
+			http.StatusInternalServerError,
+			"oops",
+			func(err error) bool {
+				var e *HTTPError
+				return errors.As(err, &e) && e.StatusCode == http.StatusInternalServerError && e.Body == "oops"
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		err := responseError(test.statusCode, []byte(test.body))
+
+		if !test.check(err) {
+			t.Errorf("%v: unexpected error %#v", test.name, err)
+		}
+	}
+}
@@ -20,3 +20,7 @@ func (s *OutStatement) GetCompiled() string {
 func (s *OutStatement) GetSetName() string {
 	return ""
 }
+
+func (s *OutStatement) Validate() error {
+	return nil
+}
@@ -18,3 +18,7 @@ func (s *RecurseStatement) GetCompiled() string {
 func (s *RecurseStatement) GetSetName() string {
 	return ""
 }
+
+func (s *RecurseStatement) Validate() error {
+	return nil
+}
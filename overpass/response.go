@@ -0,0 +1,293 @@
+package overpass
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Response is a decoded Overpass response, covering every VebosityType. Fields that the verbosity
+// used does not output are left at their zero value, e.g. Tags for skel or Lat/Lon for tags.
+type Response struct {
+	Version   float64   `json:"version"`
+	Generator string    `json:"generator"`
+	OSM3S     OSM3S     `json:"osm3s"`
+	Remark    string    `json:"remark,omitempty"` // Runtime remarks and errors reported by the server
+	Bounds    *Bounds   `json:"bounds,omitempty"` // Bounding box of the query, only output in XML
+	Elements  []Element `json:"-"`                // Elements in the order they were returned
+}
+
+type OSM3S struct {
+	TimestampOSMBase   string `json:"timestamp_osm_base"`
+	TimestampAreasBase string `json:"timestamp_areas_base,omitempty"`
+	Copyright          string `json:"copyright"`
+}
+
+// Element is implemented by *NodeElement, *WayElement, *RelationElement, *AreaElement,
+// *CountElement and *DerivedElement
+type Element interface {
+	GetType() ElementType
+	GetID() int64
+	GetTags() map[string]string
+}
+
+// Fields common to every element, meta fields are only set by the meta verbosity
+type ElementMeta struct {
+	Type      ElementType       `json:"type"`
+	ID        int64             `json:"id"` // Zero when output with noids
+	Tags      map[string]string `json:"tags,omitempty"`
+	Version   int               `json:"version,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Changeset int64             `json:"changeset,omitempty"`
+	User      string            `json:"user,omitempty"`
+	UID       int64             `json:"uid,omitempty"`
+}
+
+func (e *ElementMeta) GetType() ElementType {
+	return e.Type
+}
+
+func (e *ElementMeta) GetID() int64 {
+	return e.ID
+}
+
+func (e *ElementMeta) GetTags() map[string]string {
+	return e.Tags
+}
+
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type Bounds struct {
+	MinLat float64 `json:"minlat"`
+	MinLon float64 `json:"minlon"`
+	MaxLat float64 `json:"maxlat"`
+	MaxLon float64 `json:"maxlon"`
+}
+
+type NodeElement struct {
+	ElementMeta
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type WayElement struct {
+	ElementMeta
+	Nodes    []int64  `json:"nodes,omitempty"`    // Ids of the member nodes, output by skel, body and meta
+	Geometry []LatLon `json:"geometry,omitempty"` // Coordinates of the member nodes, output by geom
+	Bounds   *Bounds  `json:"bounds,omitempty"`   // Output by geom and bb
+	Center   *LatLon  `json:"center,omitempty"`   // Output by center
+}
+
+type RelationElement struct {
+	ElementMeta
+	Members []Member `json:"members,omitempty"`
+	Bounds  *Bounds  `json:"bounds,omitempty"` // Output by geom and bb
+	Center  *LatLon  `json:"center,omitempty"` // Output by center
+}
+
+type Member struct {
+	Type     ElementType `json:"type"`
+	Ref      int64       `json:"ref"`
+	Role     string      `json:"role"`
+	Lat      float64     `json:"lat,omitempty"`      // Coordinates of node members, output by geom
+	Lon      float64     `json:"lon,omitempty"`      // Coordinates of node members, output by geom
+	Geometry []LatLon    `json:"geometry,omitempty"` // Coordinates of way members, output by geom
+}
+
+type AreaElement struct {
+	ElementMeta
+}
+
+// CountElement is output by the count verbosity, the counts are parsed from its tags
+type CountElement struct {
+	ElementMeta
+	Nodes     int `json:"-"`
+	Ways      int `json:"-"`
+	Relations int `json:"-"`
+	Areas     int `json:"-"`
+	Total     int `json:"-"`
+}
+
+// DerivedElement holds elements of any other type, e.g. those created by make and convert statements
+type DerivedElement struct {
+	ElementMeta
+}
+
+// Returns the node elements of the response
+func (r *Response) Nodes() []*NodeElement {
+	var nodes []*NodeElement
+	for _, e := range r.Elements {
+		if n, ok := e.(*NodeElement); ok {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Returns the way elements of the response
+func (r *Response) Ways() []*WayElement {
+	var ways []*WayElement
+	for _, e := range r.Elements {
+		if w, ok := e.(*WayElement); ok {
+			ways = append(ways, w)
+		}
+	}
+	return ways
+}
+
+// Returns the relation elements of the response
+func (r *Response) Relations() []*RelationElement {
+	var relations []*RelationElement
+	for _, e := range r.Elements {
+		if rel, ok := e.(*RelationElement); ok {
+			relations = append(relations, rel)
+		}
+	}
+	return relations
+}
+
+// Returns the area elements of the response
+func (r *Response) Areas() []*AreaElement {
+	var areas []*AreaElement
+	for _, e := range r.Elements {
+		if a, ok := e.(*AreaElement); ok {
+			areas = append(areas, a)
+		}
+	}
+	return areas
+}
+
+// Returns the count elements of the response
+func (r *Response) Counts() []*CountElement {
+	var counts []*CountElement
+	for _, e := range r.Elements {
+		if c, ok := e.(*CountElement); ok {
+			counts = append(counts, c)
+		}
+	}
+	return counts
+}
+
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type response Response
+	aux := struct {
+		*response
+		Elements []json.RawMessage `json:"elements"`
+	}{response: (*response)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Elements = make([]Element, 0, len(aux.Elements))
+
+	for _, raw := range aux.Elements {
+		e, err := decodeJSONElement(raw)
+		if err != nil {
+			return err
+		}
+		r.Elements = append(r.Elements, e)
+	}
+
+	return nil
+}
+
+// Decodes a single element of the "elements" array into its typed form
+func decodeJSONElement(raw json.RawMessage) (Element, error) {
+	var probe struct {
+		Type ElementType `json:"type"`
+	}
+
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("failed to decode Overpass element: %w", err)
+	}
+
+	var e Element
+
+	switch probe.Type {
+	case Node:
+		e = new(NodeElement)
+	case Way:
+		e = new(WayElement)
+	case Relation:
+		e = new(RelationElement)
+	case Area:
+		e = new(AreaElement)
+	case countElementType:
+		e = new(CountElement)
+	default:
+		e = new(DerivedElement)
+	}
+
+	if err := json.Unmarshal(raw, e); err != nil {
+		return nil, fmt.Errorf("failed to decode Overpass %s element: %w", probe.Type, err)
+	}
+
+	if c, ok := e.(*CountElement); ok {
+		c.parseCounts()
+	}
+
+	return e, nil
+}
+
+const countElementType ElementType = "count"
+
+func (e *CountElement) parseCounts() {
+	e.Nodes, _ = strconv.Atoi(e.Tags["nodes"])
+	e.Ways, _ = strconv.Atoi(e.Tags["ways"])
+	e.Relations, _ = strconv.Atoi(e.Tags["relations"])
+	e.Areas, _ = strconv.Atoi(e.Tags["areas"])
+	e.Total, _ = strconv.Atoi(e.Tags["total"])
+}
+
+// Decodes an Overpass [out:json] response. Runtime errors reported in the response remark are
+// returned as typed errors (see QueryBytes) alongside the decoded response.
+func DecodeJSON(r io.Reader) (*Response, error) {
+	resp := new(Response)
+
+	if err := json.NewDecoder(r).Decode(resp); err != nil {
+		return nil, fmt.Errorf("failed to decode Overpass JSON response: %w", err)
+	}
+
+	return resp, remarkError(resp.Remark)
+}
+
+// Decodes an Overpass [out:xml] response (an OSM XML document) into the same model as DecodeJSON.
+// Runtime errors reported in the response remark are returned as typed errors (see QueryBytes)
+// alongside the decoded response.
+func DecodeXML(r io.Reader) (*Response, error) {
+	d := NewElementDecoder(r, XML)
+	var elements []Element
+
+	err := d.Each(func(e Element) error {
+		elements = append(elements, e)
+		return nil
+	})
+
+	resp := d.Header()
+	resp.Elements = elements
+
+	// Decoding failures discard the response, runtime errors are returned alongside it
+	if err != nil && remarkError(resp.Remark) == nil {
+		return nil, err
+	}
+
+	return resp, err
+}
+
+// Decodes an Overpass response of the given output format, only JSON and XML are supported
+func Decode(r io.Reader, outType OutType) (*Response, error) {
+	switch outType {
+	case JSON:
+		return DecodeJSON(r)
+	case XML:
+		return DecodeXML(r)
+	default:
+		return nil, fmt.Errorf("cannot decode Overpass %s output into a Response, only json and xml are supported", outType)
+	}
+}
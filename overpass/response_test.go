@@ -0,0 +1,120 @@
+package overpass
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testJSONResponse = `{
+  "version": 0.6,
+  "generator": "Overpass API 0.7.59 e21c39fe",
+  "osm3s": {
+    "timestamp_osm_base": "2022-11-15T12:23:09Z",
+    "copyright": "The data included in this document is from www.openstreetmap.org. The data is made available under ODbL."
+  },
+  "elements": [
+    {"type": "node", "id": 1, "lat": -37.74, "lon": 144.93, "timestamp": "2020-01-01T00:00:00Z", "version": 3, "changeset": 42, "user": "mapper", "uid": 7, "tags": {"highway": "traffic_signals"}},
+    {"type": "node", "id": 2, "lat": -37.75, "lon": 144.94},
+    {"type": "way", "id": 10, "nodes": [1, 2], "tags": {"highway": "residential"}, "bounds": {"minlat": -37.75, "minlon": 144.93, "maxlat": -37.74, "maxlon": 144.94}, "geometry": [{"lat": -37.74, "lon": 144.93}, null]},
+    {"type": "way", "id": 11, "center": {"lat": -37.745, "lon": 144.935}},
+    {"type": "relation", "id": 100, "members": [{"type": "way", "ref": 10, "role": "from", "geometry": [{"lat": -37.74, "lon": 144.93}]}, {"type": "node", "ref": 1, "role": "via", "lat": -37.74, "lon": 144.93}], "tags": {"type": "restriction"}},
+    {"type": "area", "id": 3600000100, "tags": {"name": "Melbourne"}},
+    {"type": "count", "id": 0, "tags": {"nodes": "2", "ways": "2", "relations": "1", "areas": "1", "total": "6"}}
+  ]
+}`
+
+func TestDecodeJSON(t *testing.T) {
+	resp, err := DecodeJSON(strings.NewReader(testJSONResponse))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Elements) != 7 {
+		t.Fatalf("expected 7 elements, got %d", len(resp.Elements))
+	}
+
+	nodes := resp.Nodes()
+	if len(nodes) != 2 || nodes[0].Lat != -37.74 || nodes[0].User != "mapper" || nodes[0].Timestamp.Year() != 2020 {
+		t.Errorf("unexpected nodes %+v", nodes)
+	}
+
+	ways := resp.Ways()
+	if len(ways) != 2 || len(ways[0].Nodes) != 2 || ways[0].Bounds == nil || len(ways[0].Geometry) != 2 || ways[1].Center == nil {
+		t.Errorf("unexpected ways %+v", ways)
+	}
+
+	relations := resp.Relations()
+	if len(relations) != 1 || relations[0].Members[0].Role != "from" || len(relations[0].Members[0].Geometry) != 1 || relations[0].Members[1].Lat != -37.74 {
+		t.Errorf("unexpected relations %+v", relations)
+	}
+
+	if areas := resp.Areas(); len(areas) != 1 || areas[0].GetTags()["name"] != "Melbourne" {
+		t.Errorf("unexpected areas %+v", areas)
+	}
+
+	if counts := resp.Counts(); len(counts) != 1 || counts[0].Total != 6 || counts[0].Ways != 2 {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestExecuteJSON(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testJSONResponse)
+	})
+
+	stack := NewStackStatement(
+		NewSettingsStatement(*NewSetting(Out, JSON)),
+		NewElementStatement(Node, []TagFilter{}, NewElementFilter(IDFilter, 1)),
+		NewOutStatement(Body),
+	).WithClient(client)
+
+	resp, err := stack.ExecuteJSON()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Elements) != 7 {
+		t.Errorf("expected 7 elements, got %d", len(resp.Elements))
+	}
+
+	xml := NewStackStatement(NewElementStatement(Node, []TagFilter{}), NewOutStatement(Body)).WithClient(client)
+
+	if _, err := xml.ExecuteJSON(); err == nil {
+		t.Errorf("expected an error executing an XML query with ExecuteJSON")
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	fromXML, err := DecodeXML(strings.NewReader(testXMLResponse))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fromXML.Version != 0.6 || fromXML.Generator != "Overpass API 0.7.59 e21c39fe" || fromXML.OSM3S.TimestampOSMBase != "2022-11-15T12:23:09Z" || fromXML.Bounds == nil || fromXML.Bounds.MinLat != -37.76 {
+		t.Errorf("unexpected header %+v", fromXML)
+	}
+
+	fromJSON, err := DecodeJSON(strings.NewReader(testJSONResponse))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fromXML.OSM3S.Copyright != fromJSON.OSM3S.Copyright {
+		t.Errorf("copyright %q does not match %q", fromXML.OSM3S.Copyright, fromJSON.OSM3S.Copyright)
+	}
+
+	if !reflect.DeepEqual(fromXML.Elements, fromJSON.Elements) {
+		for i := range fromXML.Elements {
+			if !reflect.DeepEqual(fromXML.Elements[i], fromJSON.Elements[i]) {
+				t.Errorf("element %d decoded from XML %+v does not match JSON %+v", i, fromXML.Elements[i], fromJSON.Elements[i])
+			}
+		}
+	}
+}
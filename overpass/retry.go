@@ -0,0 +1,63 @@
+package overpass
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/captchanjack/osmdata/helpers"
+)
+
+// SlotPolicy is a helpers.RetryPolicy that retries queries rejected for lack of a free slot,
+// waiting until the next slot frees up. A query is rejected when the interpreter responds 429 (too
+// many requests) or 504 (gateway timeout, the server is too busy), or when the client's CheckStatus
+// is set and the status endpoint reports no free slot ahead of sending it. Other failures are not
+// retried.
+//
+// This is the policy used when maxAttempts is passed to Query and StackStatement.Execute.
+type SlotPolicy struct {
+	Client      *Client // Client whose status endpoint is consulted after a 429 or 504 response
+	MaxAttempts int     // Give up after this many attempts
+}
+
+// Returns a pointer to a new SlotPolicy consulting the status endpoint of client
+func NewSlotPolicy(client *Client, maxAttempts int) *SlotPolicy {
+	p := new(SlotPolicy)
+	p.Client = client
+	p.MaxAttempts = maxAttempts
+	return p
+}
+
+func (p *SlotPolicy) NextRetry(ctx context.Context, attempt helpers.RetryAttempt) (time.Duration, bool) {
+	if attempt.Attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	var rateLimitErr *RateLimitError
+	if errors.As(attempt.Err, &rateLimitErr) {
+		return rateLimitErr.Wait, true
+	}
+
+	if attempt.Response == nil || !isSlotStatusCode(attempt.Response.StatusCode) {
+		return 0, false
+	}
+
+	if wait, ok := helpers.ParseRetryAfter(attempt.Response, time.Now()); ok {
+		return wait, true
+	}
+
+	if p.Client != nil {
+		if wait, ok := p.Client.slotWait(ctx); ok {
+			return wait, true
+		}
+	}
+
+	return statusFallbackWait, true
+}
+
+// Status codes returned by the interpreter when the client has run out of slots or the server is
+// too busy, both are resolved by waiting for the next slot
+func isSlotStatusCode(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusGatewayTimeout
+}
@@ -20,3 +20,7 @@ func (s *SetStatement) GetCompiled() string {
 func (s *SetStatement) GetSetName() string {
 	return s.SetName
 }
+
+func (s *SetStatement) Validate() error {
+	return nil
+}
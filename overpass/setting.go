@@ -6,10 +6,16 @@ import (
 	"strings"
 )
 
+// Returns a pointer to a new Setting, invalid settings (e.g. Out paired with a value that is not an
+// OutType, or CSV without header options) are reported by Validate and by the Validate method of
+// any statement holding the setting. Use NewSettingE to get the error from construction.
 func NewSetting(key SettingType, value interface{}, options ...string) *Setting {
-	if _, ok := value.(OutType); key == Out && !ok {
-		panic(fmt.Errorf("key '%s' must be paired with OutType (e.g. XML, JSON, CSV), not '%s' (%s)", key, value, reflect.TypeOf(value)))
-	}
+	s, _ := NewSettingE(key, value, options...)
+	return s
+}
+
+// Same as NewSetting but also returns an error if the setting is invalid
+func NewSettingE(key SettingType, value interface{}, options ...string) (*Setting, error) {
 	s := new(Setting)
 	s.Key = key
 	s.Value = fmt.Sprintf("%v", value)
@@ -18,13 +24,30 @@ func NewSetting(key SettingType, value interface{}, options ...string) *Setting
 		s.Options = options[0]
 	}
 
-	if len(s.Options) == 0 && value == CSV {
-		panic("CSV output format requires header options (i.e. which tags/headers to keep)")
+	if _, ok := value.(OutType); key == Out && !ok {
+		s.err = fmt.Errorf("key '%s' must be paired with OutType (e.g. XML, JSON, CSV), not '%v' (%s)", key, value, reflect.TypeOf(value))
+	} else if len(s.Options) == 0 && value == CSV {
+		s.err = fmt.Errorf("CSV output format requires header options (i.e. which tags/headers to keep)")
+	}
+
+	return s, s.err
+}
+
+// Same as NewSetting but panics if the setting is invalid
+func MustNewSetting(key SettingType, value interface{}, options ...string) *Setting {
+	s := NewSetting(key, value, options...)
+
+	if err := s.Validate(); err != nil {
+		panic(err)
 	}
 
 	return s
 }
 
+func (s *Setting) Validate() error {
+	return s.err
+}
+
 func NewSettingsStatement(settings ...Setting) *SettingsStatement {
 	s := new(SettingsStatement)
 	s.Settings = settings
@@ -51,3 +74,11 @@ func (s *SettingsStatement) GetCompiled() string {
 func (s *SettingsStatement) GetSetName() string {
 	return "_"
 }
+
+func (s *SettingsStatement) Validate() error {
+	errs := make([]error, len(s.Settings))
+	for i := range s.Settings {
+		errs[i] = s.Settings[i].Validate()
+	}
+	return newValidationError(errs...)
+}
@@ -1,7 +1,11 @@
 package overpass
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -26,32 +30,253 @@ func (s *StackStatement) GetCompiled() string {
 	return s.compiled
 }
 
+// Returns the query string, or an error if any statement is invalid. Unlike GetCompiled, which
+// leaves invalid statements out, the query is never returned without them.
+func (s *StackStatement) Compile() (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	return s.compiled, nil
+}
+
 func (s *StackStatement) GetSetName() string {
 	return ""
 }
 
+// Validates every statement in the stack, reporting all problems found in a *ValidationError
+func (s *StackStatement) Validate() error {
+	errs := make([]error, len(s.Statements))
+	for i, st := range s.Statements {
+		errs[i] = st.Validate()
+	}
+	return newValidationError(errs...)
+}
+
 // Append statements and recompiles the query string
 func (s *StackStatement) Append(statements ...Statement) {
 	s.Statements = append(s.Statements, statements...)
 	s.compiled = s.compile()
 }
 
+// Sets the client used to execute the query, returns the statement to allow chaining
+func (s *StackStatement) WithClient(client *Client) *StackStatement {
+	s.Client = client
+	return s
+}
+
+func (s *StackStatement) client() *Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return DefaultClient
+}
+
 // Executes the query string
 // Specify maxAttempts to retry when rate limited, defaults to 1
 func (s *StackStatement) Execute(maxAttempts ...int) (string, error) {
-	return QueryOverpass(s.GetCompiled(), maxAttempts...)
+	return s.ExecuteContext(context.Background(), maxAttempts...)
+}
+
+// Same as Execute, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteContext(ctx context.Context, maxAttempts ...int) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	return s.client().QueryContext(ctx, s.GetCompiled(), maxAttempts...)
 }
 
 // Executes the query string and exports the data to file on disk, e.g. ./test.osm
 // Specify maxAttempts to retry when rate limited, defaults to 1
 func (s *StackStatement) ExecuteAndExport(filename string, maxAttempts ...int) (string, error) {
-	resp, err := QueryOverpassBytes(s.GetCompiled(), maxAttempts...)
+	return s.ExecuteAndExportContext(context.Background(), filename, maxAttempts...)
+}
+
+// Same as ExecuteAndExport, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteAndExportContext(ctx context.Context, filename string, maxAttempts ...int) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+
+	resp, err := s.client().QueryBytesContext(ctx, s.GetCompiled(), maxAttempts...)
 
 	if err != nil {
 		return "", err
 	}
 
-	os.WriteFile(filename, resp, 0644)
+	if err := os.WriteFile(filename, resp, 0644); err != nil {
+		return string(resp), fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return string(resp), nil
+}
+
+// Executes the query and decodes the response into a *Response, the query must request JSON
+// output, i.e. [out:json]
+// Specify maxAttempts to retry when rate limited, defaults to 1
+func (s *StackStatement) ExecuteJSON(maxAttempts ...int) (*Response, error) {
+	return s.ExecuteJSONContext(context.Background(), maxAttempts...)
+}
+
+// Same as ExecuteJSON, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteJSONContext(ctx context.Context, maxAttempts ...int) (*Response, error) {
+	if outType := s.GetOutType(); outType != JSON {
+		return nil, fmt.Errorf("ExecuteJSON requires [out:json], query outputs %s", outType)
+	}
+
+	return s.ExecuteResponseContext(ctx, maxAttempts...)
+}
+
+// Executes the query and decodes the response into a *Response, whichever of JSON or XML output
+// the query requests
+// Specify maxAttempts to retry when rate limited, defaults to 1
+func (s *StackStatement) ExecuteResponse(maxAttempts ...int) (*Response, error) {
+	return s.ExecuteResponseContext(context.Background(), maxAttempts...)
+}
+
+// Same as ExecuteResponse, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteResponseContext(ctx context.Context, maxAttempts ...int) (*Response, error) {
+	outType := s.GetOutType()
+
+	if outType != JSON && outType != XML {
+		return nil, fmt.Errorf("cannot decode Overpass %s output into a Response, only json and xml are supported", outType)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	stream, err := s.client().QueryStreamContext(ctx, s.GetCompiled(), maxAttempts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer stream.Close()
+	resp, err := Decode(stream, outType)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return resp, err
+}
+
+// Executes the query and calls handler for every element as it is decoded, without holding the
+// response in memory. The query must request JSON or XML output.
+// Specify maxAttempts to retry when rate limited, defaults to 1
+func (s *StackStatement) ExecuteStream(handler ElementHandler, maxAttempts ...int) error {
+	return s.ExecuteStreamContext(context.Background(), handler, maxAttempts...)
+}
+
+// Same as ExecuteStream, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteStreamContext(ctx context.Context, handler ElementHandler, maxAttempts ...int) error {
+	return s.executeStream(ctx, handler, "", maxAttempts...)
+}
+
+// Same as ExecuteStream but also writes the raw response to file on disk as it is decoded, e.g.
+// ./test.osm
+// Specify maxAttempts to retry when rate limited, defaults to 1
+func (s *StackStatement) ExecuteStreamAndExport(filename string, handler ElementHandler, maxAttempts ...int) error {
+	return s.ExecuteStreamAndExportContext(context.Background(), filename, handler, maxAttempts...)
+}
+
+// Same as ExecuteStreamAndExport, cancelling ctx aborts the request and returns ctx.Err()
+func (s *StackStatement) ExecuteStreamAndExportContext(ctx context.Context, filename string, handler ElementHandler, maxAttempts ...int) error {
+	return s.executeStream(ctx, handler, filename, maxAttempts...)
+}
+
+func (s *StackStatement) executeStream(ctx context.Context, handler ElementHandler, filename string, maxAttempts ...int) error {
+	outType := s.GetOutType()
+
+	if outType != JSON && outType != XML {
+		return fmt.Errorf("cannot stream Overpass %s output, only json and xml are supported", outType)
+	}
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	stream, err := s.client().QueryStreamContext(ctx, s.GetCompiled(), maxAttempts...)
+
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close()
+	var r io.Reader = stream
+
+	var f *os.File
+
+	if filename != "" {
+		// Write to a temporary file next to the export and rename it once the response has been
+		// read in full, so a failed stream does not leave a truncated export behind
+		f, err = os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+
+		defer func() {
+			f.Close()
+			os.Remove(f.Name())
+		}()
+
+		r = io.TeeReader(stream, f)
+	}
+
+	err = NewElementDecoder(r, outType).Each(handler)
+
+	// The decoder may stop short of trailing whitespace, export the response in full
+	if err == nil && f != nil {
+		_, err = io.Copy(io.Discard, r)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err != nil || f == nil {
+		return err
+	}
+
+	if err := f.Chmod(0644); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the output format requested by the settings of the stack, XML if not set
+func (s *StackStatement) GetOutType() OutType {
+	if setting := s.outSetting(); setting != nil {
+		return OutType(setting.Value)
+	}
+	return XML
+}
+
+// Returns the out setting of the stack, nil if not set
+func (s *StackStatement) outSetting() *Setting {
+	for _, st := range s.Statements {
+		settings, ok := st.(*SettingsStatement)
+
+		if !ok {
+			continue
+		}
+
+		for i := range settings.Settings {
+			if settings.Settings[i].Key == Out {
+				return &settings.Settings[i]
+			}
+		}
+	}
 
-	return string(resp), err
+	return nil
 }
@@ -0,0 +1,202 @@
+package overpass
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status of an Overpass API instance as reported by its /api/status endpoint, e.g.
+//
+//	Connected as: 1234567890
+//	Current time: 2022-11-15T12:23:09Z
+//	Announced endpoint: none
+//	Rate limit: 2
+//	Slot available after: 2022-11-15T12:23:35Z, in 26 seconds.
+//	Currently running queries (pid, space limit, time limit, start time):
+//	12345	536870912	180	2022-11-15T12:23:00Z
+type Status struct {
+	ConnectedAs         string         // Id the server uses to rate limit this client
+	CurrentTime         time.Time      // Server time the status was generated at
+	AnnouncedEndpoint   string         // Endpoint the server announces for load balancing, "none" if not announced
+	RateLimit           int            // Number of slots available to this client, zero means unlimited
+	SlotsAvailable      int            // Number of slots free right now
+	SlotsAvailableAfter []time.Time    // Times at which the currently occupied slots free up
+	RunningQueries      []RunningQuery // Queries from this client currently running on the server
+}
+
+type RunningQuery struct {
+	PID        int
+	SpaceLimit int64         // Memory limit in bytes
+	TimeLimit  time.Duration // Query timeout
+	StartTime  time.Time
+}
+
+// Fallback wait when no slot is free but the status does not say when one will be
+const statusFallbackWait = 5 * time.Second
+
+// Returns how long to wait until a slot is available to this client, zero if a slot is
+// available now.
+func (s *Status) NextSlot() time.Duration {
+	if s.RateLimit == 0 || s.SlotsAvailable > 0 {
+		return 0
+	}
+
+	if len(s.SlotsAvailableAfter) == 0 {
+		return statusFallbackWait
+	}
+
+	next := s.SlotsAvailableAfter[0]
+	for _, t := range s.SlotsAvailableAfter[1:] {
+		if t.Before(next) {
+			next = t
+		}
+	}
+
+	// Measured against the server clock so local clock skew does not matter
+	wait := next.Sub(s.CurrentTime)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// Parses the plain text body returned by the Overpass /api/status endpoint
+func ParseStatus(r io.Reader) (*Status, error) {
+	s := new(Status)
+	scanner := bufio.NewScanner(r)
+	inRunningQueries := false
+	sawRateLimit := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if inRunningQueries {
+			q, err := parseRunningQuery(line)
+			if err != nil {
+				return nil, err
+			}
+			s.RunningQueries = append(s.RunningQueries, q)
+			continue
+		}
+
+		var err error
+
+		switch {
+		case strings.HasPrefix(line, "Connected as:"):
+			s.ConnectedAs = strings.TrimSpace(strings.TrimPrefix(line, "Connected as:"))
+
+		case strings.HasPrefix(line, "Current time:"):
+			s.CurrentTime, err = time.Parse(time.RFC3339, strings.TrimSpace(strings.TrimPrefix(line, "Current time:")))
+
+		case strings.HasPrefix(line, "Announced endpoint:"):
+			s.AnnouncedEndpoint = strings.TrimSpace(strings.TrimPrefix(line, "Announced endpoint:"))
+
+		case strings.HasPrefix(line, "Rate limit:"):
+			s.RateLimit, err = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Rate limit:")))
+			sawRateLimit = true
+
+		case strings.HasSuffix(line, "slots available now.") || strings.HasSuffix(line, "slot available now."):
+			s.SlotsAvailable, err = strconv.Atoi(strings.Fields(line)[0])
+
+		case strings.HasPrefix(line, "Slot available after:"):
+			// e.g. "Slot available after: 2022-11-15T12:23:35Z, in 26 seconds."
+			value := strings.TrimSpace(strings.TrimPrefix(line, "Slot available after:"))
+			if i := strings.Index(value, ","); i >= 0 {
+				value = value[:i]
+			}
+			var t time.Time
+			t, err = time.Parse(time.RFC3339, value)
+			s.SlotsAvailableAfter = append(s.SlotsAvailableAfter, t)
+
+		case strings.HasPrefix(line, "Currently running queries"):
+			inRunningQueries = true
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Overpass status line %q: %w", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Overpass status: %w", err)
+	}
+
+	if !sawRateLimit {
+		return nil, fmt.Errorf("response is not an Overpass status, missing rate limit")
+	}
+
+	return s, nil
+}
+
+func parseRunningQuery(line string) (RunningQuery, error) {
+	var q RunningQuery
+	fields := strings.Fields(line)
+
+	if len(fields) < 4 {
+		return q, fmt.Errorf("failed to parse Overpass running query %q: expected 4 fields", line)
+	}
+
+	var err error
+	var seconds int
+
+	if q.PID, err = strconv.Atoi(fields[0]); err != nil {
+		return q, fmt.Errorf("failed to parse Overpass running query %q: %w", line, err)
+	}
+
+	if q.SpaceLimit, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return q, fmt.Errorf("failed to parse Overpass running query %q: %w", line, err)
+	}
+
+	if seconds, err = strconv.Atoi(fields[2]); err != nil {
+		return q, fmt.Errorf("failed to parse Overpass running query %q: %w", line, err)
+	}
+	q.TimeLimit = time.Duration(seconds) * time.Second
+
+	if q.StartTime, err = time.Parse(time.RFC3339, fields[3]); err != nil {
+		return q, fmt.Errorf("failed to parse Overpass running query %q: %w", line, err)
+	}
+
+	return q, nil
+}
+
+// Fetches and parses the status of the Overpass instance
+func (c *Client) Status() (*Status, error) {
+	return c.StatusContext(context.Background())
+}
+
+// Same as Status, cancelling ctx aborts the request and returns ctx.Err()
+func (c *Client) StatusContext(ctx context.Context) (*Status, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.StatusURL, nil)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("encountered error during GET request to Overpass Status API: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("overpass status error (status code %v):\n%s", resp.StatusCode, body)
+	}
+
+	status, err := ParseStatus(resp.Body)
+
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return status, err
+}
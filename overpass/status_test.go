@@ -0,0 +1,143 @@
+package overpass
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatus(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		body           string
+		slotsAvailable int
+		runningQueries int
+		nextSlot       time.Duration
+	}{
+		{
+			"available",
+			"Connected as: 1234567890\nCurrent time: 2022-11-15T12:23:09Z\nAnnounced endpoint: none\nRate limit: 2\n2 slots available now.\nCurrently running queries (pid, space limit, time limit, start time):\n",
+			2,
+			0,
+			0,
+		},
+		{
+			"rate limited",
+			"Connected as: 1234567890\nCurrent time: 2022-11-15T12:23:09Z\nAnnounced endpoint: none\nRate limit: 2\nSlot available after: 2022-11-15T12:23:35Z, in 26 seconds.\nSlot available after: 2022-11-15T12:23:19Z, in 10 seconds.\nCurrently running queries (pid, space limit, time limit, start time):\n12345\t536870912\t180\t2022-11-15T12:23:00Z\n",
+			0,
+			1,
+			10 * time.Second,
+		},
+		{
+			"unlimited",
+			"Connected as: 1234567890\nCurrent time: 2022-11-15T12:23:09Z\nRate limit: 0\nCurrently running queries (pid, space limit, time limit, start time):\n",
+			0,
+			0,
+			0,
+		},
+	}
+
+	for _, test := range testCases {
+		status, err := ParseStatus(strings.NewReader(test.body))
+
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+
+		if status.ConnectedAs != "1234567890" {
+			t.Errorf("%v: connected as %q", test.name, status.ConnectedAs)
+		}
+
+		if status.SlotsAvailable != test.slotsAvailable {
+			t.Errorf("%v: expected %d slots available, got %d", test.name, test.slotsAvailable, status.SlotsAvailable)
+		}
+
+		if len(status.RunningQueries) != test.runningQueries {
+			t.Errorf("%v: expected %d running queries, got %d", test.name, test.runningQueries, len(status.RunningQueries))
+		}
+
+		if status.NextSlot() != test.nextSlot {
+			t.Errorf("%v: expected next slot in %v, got %v", test.name, test.nextSlot, status.NextSlot())
+		}
+	}
+
+	if _, err := ParseStatus(strings.NewReader("<html>Not Found</html>")); err == nil {
+		t.Errorf("expected error parsing a non-status body")
+	}
+}
+
+func TestClientWaitsForSlot(t *testing.T) {
+	statusCalls, interpreterCalls := 0, 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		statusCalls++
+		if statusCalls == 1 {
+			io.WriteString(w, "Connected as: 1\nCurrent time: 2022-11-15T12:23:09Z\nRate limit: 2\nSlot available after: 2022-11-15T12:23:09Z, in 0 seconds.\nCurrently running queries (pid, space limit, time limit, start time):\n")
+			return
+		}
+		io.WriteString(w, "Connected as: 1\nCurrent time: 2022-11-15T12:23:09Z\nRate limit: 2\n1 slots available now.\nCurrently running queries (pid, space limit, time limit, start time):\n")
+	})
+	mux.HandleFunc("/api/interpreter", func(w http.ResponseWriter, r *http.Request) {
+		interpreterCalls++
+		if interpreterCalls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(server.URL+"/api/interpreter", server.URL+"/api/status")
+	resp, err := client.Query("node(1);out;", 3)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp != "ok" {
+		t.Errorf("unexpected response %q", resp)
+	}
+
+	if interpreterCalls != 2 {
+		t.Errorf("expected the 429 response to be retried once, interpreter called %d times", interpreterCalls)
+	}
+}
+
+func TestClientCheckStatus(t *testing.T) {
+	interpreterCalls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Connected as: 1\nCurrent time: 2022-11-15T12:23:09Z\nRate limit: 2\nSlot available after: 2022-11-15T12:23:39Z, in 30 seconds.\nSlot available after: 2022-11-15T12:23:49Z, in 40 seconds.\nCurrently running queries (pid, space limit, time limit, start time):\n")
+	})
+	mux.HandleFunc("/api/interpreter", func(w http.ResponseWriter, r *http.Request) {
+		interpreterCalls++
+		io.WriteString(w, "ok")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Queries are sent regardless of the status by default
+	client := NewClient(server.URL+"/api/interpreter", server.URL+"/api/status")
+
+	if resp, err := client.Query("node(1);out;"); err != nil || resp != "ok" {
+		t.Errorf("expected the query to be sent, got %q, %v", resp, err)
+	}
+
+	client.CheckStatus = true
+	_, err := client.Query("node(1);out;")
+
+	if _, ok := err.(*RateLimitError); !ok {
+		t.Errorf("expected *RateLimitError without a free slot, got %v", err)
+	}
+
+	if interpreterCalls != 1 {
+		t.Errorf("expected the query to be refused before sending, interpreter called %d times", interpreterCalls)
+	}
+}
@@ -4,6 +4,7 @@ type Statement interface {
 	compile() string
 	GetCompiled() string
 	GetSetName() string
+	Validate() error // Reports every problem with the statement and the statements it wraps
 }
 
 type ElementStatement struct {
@@ -17,6 +18,7 @@ type UnionStatement struct {
 	SetName    string `default:"_"`
 	Statements []Statement
 	compiled   string
+	err        error
 }
 
 type DifferenceStatement struct {
@@ -24,6 +26,7 @@ type DifferenceStatement struct {
 	FirstStatement  Statement
 	SecondStatement Statement
 	compiled        string
+	err             error
 }
 
 type SettingsStatement struct {
@@ -48,6 +51,7 @@ type RecurseStatement struct {
 
 type StackStatement struct {
 	Statements []Statement
+	Client     *Client // Client used by Execute and ExecuteAndExport, DefaultClient when nil
 	compiled   string
 }
 
@@ -60,12 +64,15 @@ type TagFilter struct {
 type ElementFilter struct {
 	ElementFilterType ElementFilterType
 	FilterStr         string
+	err               error
 }
 
 type Setting struct {
 	Key     SettingType
 	Value   string
 	Options string
+	csv     *CSVOptions
+	err     error
 }
 
 type ElementType string
@@ -153,7 +160,9 @@ const (
 	BoundingBoxFilter ElementFilterType = "BoundingBoxFilter" // The bounding box query filter selects all elements within a rectangular bounding box, (south,west,north,east), e.g. node(50.6,7.0,50.8,7.3);
 	RecurseFilter     ElementFilterType = "RecurseFilter"     // The recurse filter selects all elements that are members of an element from the input set or have an element of the input set as member, depending on the given parameter, e.g. node(w)
 	IDFilter          ElementFilterType = "IDFilter"          // The id-query filter selects the element of given type with given id
+	Int64IDFilter     ElementFilterType = "Int64IDFilter"     // Same as IDFilter but takes int64 ids, which fit area ids on 32-bit platforms
 	AroundFilter      ElementFilterType = "AroundFilter"      // The around filter selects all elements within a certain radius in metres around the elements in the input set
 	PolygonFilter     ElementFilterType = "PolygonFilter"     // The third variant selects all elements that have a tag with a certain key and a value that matches some regular expression
-	AreaFilter        ElementFilterType = "AreaFilter"        // The pivot filter selects the element of the chosen type that defines the outline of the given area
+	AreaFilter        ElementFilterType = "AreaFilter"        // The area filter selects all elements within the given area, e.g. way(area:3600002402)
+	AreaIDFilter      ElementFilterType = "AreaIDFilter"      // Same as AreaFilter but takes int64 area ids, which fit area ids derived from relations on 32-bit platforms
 )
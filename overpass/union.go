@@ -6,27 +6,40 @@ import (
 	"strings"
 )
 
+// Returns a pointer to a new UnionStatement, unions and differences cannot be nested inside a union,
+// such statements and nil statements are left out of the compiled query and reported by Validate. Use
+// NewUnionStatementE to get the error from construction.
 func NewUnionStatement(resultSetName string, statements ...Statement) *UnionStatement {
+	s, _ := NewUnionStatementE(resultSetName, statements...)
+	return s
+}
+
+// Same as NewUnionStatement but also returns an error if a statement is nil or is a union or
+// difference
+func NewUnionStatementE(resultSetName string, statements ...Statement) (*UnionStatement, error) {
 	s := new(UnionStatement)
 	if resultSetName != "" {
 		s.SetName = resultSetName
 	}
 	s.Statements = statements
 	s.compiled = s.compile()
-	return s
+	return s, s.err
 }
 
+// Compiles the union and records nil and nested block statements in s.err
 func (s *UnionStatement) compile() string {
 	var c strings.Builder
+	var errs []error
 
 	c.WriteString("(")
 
 	for _, _s := range s.Statements {
-		_, isUnion := _s.(*UnionStatement)
-		_, isDiff := _s.(*DifferenceStatement)
-
-		if isUnion || isDiff {
-			panic(fmt.Errorf("%s cannot wrap %s", reflect.TypeOf(s), reflect.TypeOf(_s)))
+		if _s == nil {
+			errs = append(errs, fmt.Errorf("%s cannot wrap a nil statement", reflect.TypeOf(s)))
+			continue
+		} else if isBlockStatement(_s) {
+			errs = append(errs, fmt.Errorf("%s cannot wrap %s", reflect.TypeOf(s), reflect.TypeOf(_s)))
+			continue
 		}
 
 		c.WriteString(_s.GetCompiled())
@@ -34,6 +47,8 @@ func (s *UnionStatement) compile() string {
 
 	c.WriteString(fmt.Sprintf(")->.%s;", s.SetName))
 
+	s.err = newValidationError(errs...)
+
 	return c.String()
 }
 
@@ -45,7 +60,38 @@ func (s *UnionStatement) GetSetName() string {
 	return s.SetName
 }
 
-// Append statements and recompiles the query string
+// Same as NewUnionStatement but panics if the union is invalid
+func MustNewUnionStatement(resultSetName string, statements ...Statement) *UnionStatement {
+	s := NewUnionStatement(resultSetName, statements...)
+
+	if err := s.Validate(); err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func (s *UnionStatement) Validate() error {
+	errs := []error{s.err}
+
+	for _, _s := range s.Statements {
+		if _s != nil && !isBlockStatement(_s) {
+			errs = append(errs, _s.Validate())
+		}
+	}
+
+	return newValidationError(errs...)
+}
+
+// Unions and differences are block statements that cannot be nested in one another
+func isBlockStatement(s Statement) bool {
+	_, isUnion := s.(*UnionStatement)
+	_, isDiff := s.(*DifferenceStatement)
+	return isUnion || isDiff
+}
+
+// Append statements and recompiles the query string, nil statements and unions or differences are
+// left out and reported by Validate
 func (s *UnionStatement) Append(statements ...Statement) {
 	s.Statements = append(s.Statements, statements...)
 	s.compiled = s.compile()
@@ -0,0 +1,64 @@
+package overpass
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidationError collects every problem found while validating a statement, errors.Is and
+// errors.As match any of the collected errors
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "invalid statement: " + strings.Join(messages, "; ")
+}
+
+// Reports whether any of the collected errors matches target, errors.Is only follows a slice of
+// wrapped errors from Go 1.20 so the errors are walked here
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Finds the first collected error that matches target, see Is
+func (e *ValidationError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns nil if errs holds no errors, otherwise a *ValidationError holding all of them with nested
+// validation errors flattened
+func newValidationError(errs ...error) error {
+	var flat []error
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if v, ok := err.(*ValidationError); ok {
+			flat = append(flat, v.Errors...)
+		} else {
+			flat = append(flat, err)
+		}
+	}
+
+	if len(flat) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: flat}
+}
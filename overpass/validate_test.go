@@ -0,0 +1,111 @@
+package overpass
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	nested := NewUnionStatement("a", NewElementStatement(Node, []TagFilter{}))
+	badFilter := NewElementFilter(AroundFilter, "500", [][]float64{{144.93, -37.74}})
+
+	stack := NewStackStatement(
+		NewSettingsStatement(*NewSetting(Out, "json"), *NewSetting(Out, CSV)),
+		NewUnionStatement("_", nested, NewElementStatement(Way, []TagFilter{}, badFilter)),
+		NewDifferenceStatement("d", nested, NewSetStatement("a")),
+		NewOutStatement(Body),
+	)
+
+	err := stack.Validate()
+
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	if len(v.Errors) != 5 {
+		t.Errorf("expected 5 problems, got %d: %v", len(v.Errors), err)
+	}
+
+	// The collected errors are found through the validation error
+	sentinel := errors.New("sentinel")
+	wrapped := newValidationError(v, fmt.Errorf("wrapped: %w", sentinel), &RateLimitError{})
+
+	var rateLimitErr *RateLimitError
+	if !errors.Is(wrapped, sentinel) || !errors.As(wrapped, &rateLimitErr) || errors.Is(wrapped, errors.New("other")) {
+		t.Errorf("expected errors.Is and errors.As to find the collected errors of %v", wrapped)
+	}
+
+	if _, err := stack.Execute(); err == nil {
+		t.Errorf("expected Execute to report validation errors")
+	}
+
+	valid := NewStackStatement(
+		NewSettingsStatement(*NewSetting(Out, JSON)),
+		NewUnionStatement("_", NewElementStatement(Way, []TagFilter{}, NewElementFilter(AroundFilter, 500.0, [][]float64{{144.93, -37.74}}))),
+		NewOutStatement(Body),
+	)
+
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMustPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustNewSetting to panic")
+		}
+	}()
+
+	MustNewSetting(Out, CSV)
+}
+
+func TestConstructionErrors(t *testing.T) {
+	nested := NewUnionStatement("a", NewElementStatement(Node, []TagFilter{}))
+
+	if _, err := NewUnionStatementE("_", nested); err == nil {
+		t.Errorf("expected NewUnionStatementE to report the nested union")
+	}
+
+	if _, err := NewDifferenceStatementE("d", nested, NewSetStatement("a")); err == nil {
+		t.Errorf("expected NewDifferenceStatementE to report the nested union")
+	}
+
+	if _, err := NewSettingE(Out, "json"); err == nil {
+		t.Errorf("expected NewSettingE to report the untyped out value")
+	}
+
+	if _, err := NewElementFilterE(AroundFilter, "500", [][]float64{{144.93, -37.74}}); err == nil {
+		t.Errorf("expected NewElementFilterE to report the mismatched args")
+	}
+
+	union := NewUnionStatement("_", NewElementStatement(Way, []TagFilter{}))
+
+	union.Append(nested)
+
+	if err := union.Validate(); err == nil {
+		t.Errorf("expected Validate to report the appended nested union")
+	}
+
+	if _, err := NewUnionStatementE("_", nil); err == nil {
+		t.Errorf("expected NewUnionStatementE to report the nil statement")
+	}
+
+	if _, err := NewDifferenceStatementE("d", nil, NewSetStatement("a")); err == nil {
+		t.Errorf("expected NewDifferenceStatementE to report the nil statement")
+	}
+
+	stack := NewStackStatement(NewSettingsStatement(*NewSetting(Out, JSON)), union, NewOutStatement(Body))
+
+	if query, err := stack.Compile(); err == nil || query != "" {
+		t.Errorf("expected Compile to report the nested union instead of %q", query)
+	}
+
+	valid := NewStackStatement(NewSettingsStatement(*NewSetting(Out, JSON)), NewOutStatement(Body))
+
+	if query, err := valid.Compile(); err != nil || query != valid.GetCompiled() {
+		t.Errorf("expected %q, got %q, %v", valid.GetCompiled(), query, err)
+	}
+}
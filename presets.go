@@ -1,6 +1,7 @@
 package osmdata
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/captchanjack/osmdata/helpers"
@@ -11,10 +12,12 @@ import (
 type QueryMethod string
 
 const (
-	PlaceName   QueryMethod = "PlaceName"
-	BoundingBox QueryMethod = "BoundingBox"
-	Radius      QueryMethod = "Radius"
-	Polygon     QueryMethod = "Polygon"
+	PlaceName    QueryMethod = "PlaceName"
+	BoundingBox  QueryMethod = "BoundingBox"
+	Radius       QueryMethod = "Radius"
+	Polygon      QueryMethod = "Polygon"
+	MultiPolygon QueryMethod = "MultiPolygon"
+	Area         QueryMethod = "Area"
 )
 
 type PresetNetworkType string
@@ -173,10 +176,47 @@ var PresetRelationTagFilters = map[PresetNetworkType][]ovp.TagFilter{
 //		presetNetworkType PresetNetworkType,
 //		includeMetadata bool,
 //		outputFormat ovp.OutType,
-//		polygonCoordinates *[][][]float64,
+//		polygonCoordinates [][][]float64,
 //		outputFormatOptions ...string,
 //	) *ovp.StackStatement
+//
+//	MultiPolygon: GetPresetQueryByMultiPolygon(
+//		presetNetworkType PresetNetworkType,
+//		includeMetadata bool,
+//		outputFormat ovp.OutType,
+//		multiPolygonCoordinates [][][][]float64,
+//		outputFormatOptions ...string,
+//	) *ovp.StackStatement
+//
+//	Area: GetPresetQueryByArea(
+//		presetNetworkType PresetNetworkType,
+//		includeMetadata bool,
+//		outputFormat ovp.OutType,
+//		areaID int64,
+//		outputFormatOptions ...string,
+//	) *ovp.StackStatement
+//
+// PlaceName, Polygon and MultiPolygon queries also accept a *helpers.SimplifyOptions right after the
+// place name or coordinates, e.g. GetPresetQuery(Polygon, Drive, false, ovp.JSON, polygon, options),
+// to simplify the polygons before the poly filters are built, see helpers.SimplifyMultiPolygon.
+// The simplified polygons cover the originals, so a superset of the elements is returned. Polygons
+// that cannot be simplified to MaxVertices, and for PlaceName failed Nominatim searches, are errors.
+//
+// Panics if the args do not match the signature of the query method or the resulting query is
+// invalid, see GetPresetQueryE to get an error instead.
 func GetPresetQuery(queryMethod QueryMethod, args ...interface{}) *ovp.StackStatement {
+	stack, err := GetPresetQueryE(queryMethod, args...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return stack
+}
+
+// Same as GetPresetQuery but returns an error if the args do not match the signature of the query
+// method or the resulting query is invalid
+func GetPresetQueryE(queryMethod QueryMethod, args ...interface{}) (*ovp.StackStatement, error) {
 	var getter interface{}
 
 	if queryMethod == PlaceName {
@@ -187,18 +227,75 @@ func GetPresetQuery(queryMethod QueryMethod, args ...interface{}) *ovp.StackStat
 		getter = GetPresetQueryByRadius
 	} else if queryMethod == Polygon {
 		getter = GetPresetQueryByPolygon
+	} else if queryMethod == MultiPolygon {
+		getter = GetPresetQueryByMultiPolygon
+	} else if queryMethod == Area {
+		getter = GetPresetQueryByArea
+	} else {
+		return nil, fmt.Errorf("unsupported query method '%s'", queryMethod)
 	}
 
-	result := helpers.ExecVaradicFunction(getter, args...)
-	return result[0].Interface().(*ovp.StackStatement)
+	if len(args) > 4 {
+		if _, ok := args[4].(*helpers.SimplifyOptions); ok {
+			if queryMethod == PlaceName {
+				getter = getPresetQueryByPlaceNameSimplified
+			} else if queryMethod == Polygon {
+				getter = getPresetQueryByPolygonSimplified
+			} else if queryMethod == MultiPolygon {
+				getter = getPresetQueryByMultiPolygonSimplified
+			} else {
+				return nil, fmt.Errorf("%s queries do not support polygon simplification", queryMethod)
+			}
+		}
+	}
+
+	result, err := helpers.ExecVaradicFunctionE(getter, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid args for %s query: %w", queryMethod, err)
+	}
+
+	if len(result) > 1 && !result[1].IsNil() {
+		return nil, result[1].Interface().(error)
+	}
+
+	stack := result[0].Interface().(*ovp.StackStatement)
+
+	if err := stack.Validate(); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
 }
 
+// Polygon coordinates are in GeoJSON Polygon format, the first ring is the exterior and the rest
+// are holes whose elements are excluded, see GetPresetQueryByMultiPolygon
 func GetPresetQueryByPolygon(
 	presetNetworkType PresetNetworkType,
 	includeMetadata bool,
 	outputFormat ovp.OutType,
 	polygonCoordinates [][][]float64,
 	outputFormatOptions ...string,
+) *ovp.StackStatement {
+	return GetPresetQueryByMultiPolygon(
+		presetNetworkType,
+		includeMetadata,
+		outputFormat,
+		[][][][]float64{polygonCoordinates},
+		outputFormatOptions...,
+	)
+}
+
+// Multipolygon coordinates are in GeoJSON MultiPolygon format, elements within any of the polygons
+// are returned. Elements within a hole of a polygon are removed with a difference against the
+// elements within its holes, so ways crossing the edge of a hole are removed as well. Long
+// boundaries can be simplified first through GetPresetQuery, see its SimplifyOptions arg.
+func GetPresetQueryByMultiPolygon(
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	multiPolygonCoordinates [][][][]float64,
+	outputFormatOptions ...string,
 ) *ovp.StackStatement {
 	settings := ovp.NewSettingsStatement(*ovp.NewSetting(ovp.Out, outputFormat, outputFormatOptions...))
 	stack := ovp.NewStackStatement(settings)
@@ -206,18 +303,37 @@ func GetPresetQueryByPolygon(
 	wayFilters := PresetWayTagFilters[presetNetworkType]
 	relationFilters := PresetRelationTagFilters[presetNetworkType]
 
-	var poly *ovp.ElementFilter
-	var way ovp.Statement
-	var relation ovp.Statement
-
 	union := ovp.NewUnionStatement("_")
 	recurse := ovp.NewRecurseStatement(ovp.RecurseDown)
 
-	for _, coords := range polygonCoordinates {
-		poly = ovp.NewElementFilter(ovp.PolygonFilter, coords)
-		way = ovp.NewElementStatement(ovp.Way, wayFilters, poly)
-		relation = ovp.NewElementStatement(ovp.Relation, relationFilters, poly)
-		union.Append(way, recurse, relation, recurse)
+	for i, polygon := range multiPolygonCoordinates {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		poly := ovp.NewElementFilter(ovp.PolygonFilter, polygon[0])
+		way := ovp.NewElementStatement(ovp.Way, wayFilters, poly)
+		relation := ovp.NewElementStatement(ovp.Relation, relationFilters, poly)
+
+		if len(polygon) == 1 {
+			union.Append(way, recurse, relation, recurse)
+			continue
+		}
+
+		wayHoles := ovp.NewUnionStatement(fmt.Sprintf("way_holes_%d", i))
+		relationHoles := ovp.NewUnionStatement(fmt.Sprintf("relation_holes_%d", i))
+
+		for _, hole := range polygon[1:] {
+			poly = ovp.NewElementFilter(ovp.PolygonFilter, hole)
+			wayHoles.Append(ovp.NewElementStatement(ovp.Way, wayFilters, poly))
+			relationHoles.Append(ovp.NewElementStatement(ovp.Relation, relationFilters, poly))
+		}
+
+		ways := ovp.NewDifferenceStatement(fmt.Sprintf("way_%d", i), way, ovp.NewSetStatement(wayHoles.SetName))
+		relations := ovp.NewDifferenceStatement(fmt.Sprintf("relation_%d", i), relation, ovp.NewSetStatement(relationHoles.SetName))
+
+		stack.Append(wayHoles, relationHoles, ways, relations)
+		union.Append(ovp.NewSetStatement(ways.SetName), recurse, ovp.NewSetStatement(relations.SetName), recurse)
 	}
 
 	body := ovp.NewOutStatement(ovp.Body)
@@ -231,6 +347,8 @@ func GetPresetQueryByPolygon(
 	return stack
 }
 
+// Searches Nominatim for the place name and returns elements within every polygon of its boundary,
+// excluding holes, see GetPresetQueryByMultiPolygon
 func GetPresetQueryByPlaceName(
 	presetNetworkType PresetNetworkType,
 	includeMetadata bool,
@@ -238,21 +356,194 @@ func GetPresetQueryByPlaceName(
 	placeName string,
 	outputFormatOptions ...string,
 ) *ovp.StackStatement {
-	polygon, err := nom.QueryNominatim(placeName)
+	polygons, err := nom.QueryNominatimPolygons(placeName)
+
+	if err != nil {
+		fmt.Println(fmt.Errorf("encountered error during GET request to Nominatim API: %s", err))
+	}
+
+	return GetPresetQueryByMultiPolygon(
+		presetNetworkType,
+		includeMetadata,
+		outputFormat,
+		polygons,
+		outputFormatOptions...,
+	)
+}
+
+func getPresetQueryByPlaceNameSimplified(
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	placeName string,
+	simplifyOptions *helpers.SimplifyOptions,
+	outputFormatOptions ...string,
+) (*ovp.StackStatement, error) {
+	polygons, err := nom.QueryNominatimPolygons(placeName)
 
 	if err != nil {
-		fmt.Println(fmt.Errorf("encountered error during POST request to Overpass API: %s", err))
+		return nil, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
 	}
 
-	return GetPresetQueryByPolygon(
+	return getPresetQueryByMultiPolygonSimplified(
+		presetNetworkType,
+		includeMetadata,
+		outputFormat,
+		polygons,
+		simplifyOptions,
+		outputFormatOptions...,
+	)
+}
+
+func getPresetQueryByPolygonSimplified(
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	polygonCoordinates [][][]float64,
+	simplifyOptions *helpers.SimplifyOptions,
+	outputFormatOptions ...string,
+) (*ovp.StackStatement, error) {
+	return getPresetQueryByMultiPolygonSimplified(
 		presetNetworkType,
 		includeMetadata,
 		outputFormat,
-		polygon,
-		outputFormatOptions...
+		[][][][]float64{polygonCoordinates},
+		simplifyOptions,
+		outputFormatOptions...,
 	)
 }
 
+// Simplifies the polygons before building the query, nil simplifyOptions leaves them as they are
+func getPresetQueryByMultiPolygonSimplified(
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	multiPolygonCoordinates [][][][]float64,
+	simplifyOptions *helpers.SimplifyOptions,
+	outputFormatOptions ...string,
+) (*ovp.StackStatement, error) {
+	if simplifyOptions != nil {
+		simplified, err := helpers.SimplifyMultiPolygon(multiPolygonCoordinates, simplifyOptions)
+
+		if err != nil {
+			return nil, fmt.Errorf("encountered error during polygon simplification: %w", err)
+		}
+
+		multiPolygonCoordinates = simplified
+	}
+
+	return GetPresetQueryByMultiPolygon(
+		presetNetworkType,
+		includeMetadata,
+		outputFormat,
+		multiPolygonCoordinates,
+		outputFormatOptions...,
+	), nil
+}
+
+// Returns elements within an Overpass area, see ovp.AreaID for deriving the area id from the id of
+// a relation or way
+func GetPresetQueryByArea(
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	areaID int64,
+	outputFormatOptions ...string,
+) *ovp.StackStatement {
+	settings := ovp.NewSettingsStatement(*ovp.NewSetting(ovp.Out, outputFormat, outputFormatOptions...))
+	wayFilters := PresetWayTagFilters[presetNetworkType]
+	relationFilters := PresetRelationTagFilters[presetNetworkType]
+	area := ovp.NewElementFilter(ovp.AreaIDFilter, areaID)
+	way := ovp.NewElementStatement(ovp.Way, wayFilters, area)
+	relation := ovp.NewElementStatement(ovp.Relation, relationFilters, area)
+	recurse := ovp.NewRecurseStatement(ovp.RecurseDown)
+	union := ovp.NewUnionStatement("_", way, recurse, relation, recurse)
+	body := ovp.NewOutStatement(ovp.Body)
+	stack := ovp.NewStackStatement(settings, union, body)
+
+	if includeMetadata {
+		stack.Append(ovp.NewOutStatement(ovp.Meta))
+	}
+
+	return stack
+}
+
+// Searches Nominatim for the place name and returns elements within the Overpass area of the
+// relation or way outlining the most relevant result with a boundary, which keeps the query short
+// for large regions. Falls back to the boundary polygons as in GetPresetQueryByPlaceName when the
+// result has no Overpass area.
+//
+// Unlike the other preset queries this sends a search to nominatimClient and an area lookup to
+// overpassClient while building the query, cancelling ctx aborts them and returns ctx.Err().
+func GetPresetQueryByPlaceNameArea(
+	ctx context.Context,
+	overpassClient *ovp.Client,
+	nominatimClient *nom.Client,
+	presetNetworkType PresetNetworkType,
+	includeMetadata bool,
+	outputFormat ovp.OutType,
+	placeName string,
+	outputFormatOptions ...string,
+) (*ovp.StackStatement, error) {
+	items, err := nominatimClient.SearchContext(ctx, placeName)
+
+	if err != nil {
+		return nil, fmt.Errorf("encountered error during GET request to Nominatim API: %w", err)
+	}
+
+	var stack *ovp.StackStatement
+
+	for _, item := range items {
+		polygons := item.GeoJSON.Polygons()
+
+		if len(polygons) == 0 {
+			continue
+		}
+
+		stack = GetPresetQueryByMultiPolygon(
+			presetNetworkType,
+			includeMetadata,
+			outputFormat,
+			polygons,
+			outputFormatOptions...,
+		)
+
+		areaID, err := ovp.AreaID(ovp.ElementType(item.OSMType), item.OSMID)
+
+		if err != nil {
+			break
+		}
+
+		exists, err := overpassClient.AreaExistsContext(ctx, areaID)
+
+		if err != nil {
+			return nil, fmt.Errorf("encountered error during Overpass area lookup: %w", err)
+		}
+
+		if exists {
+			stack = GetPresetQueryByArea(
+				presetNetworkType,
+				includeMetadata,
+				outputFormat,
+				areaID,
+				outputFormatOptions...,
+			)
+		}
+
+		break
+	}
+
+	if stack == nil {
+		return nil, fmt.Errorf("could not find suitable polygon for input place name '%s'", placeName)
+	}
+
+	if err := stack.Validate(); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
+
 func GetPresetQueryByRadius(
 	presetNetworkType PresetNetworkType,
 	includeMetadata bool,
@@ -1,12 +1,20 @@
 package osmdata
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/captchanjack/osmdata/helpers"
+	nom "github.com/captchanjack/osmdata/nominatim"
 	ovp "github.com/captchanjack/osmdata/overpass"
 )
 
@@ -139,3 +147,197 @@ func TestExport(t *testing.T) {
 
 	time.Sleep(10 * time.Second) // prevent getting rated limited
 }
+
+func TestPresetQueryE(t *testing.T) {
+	var testCases = []struct {
+		queryMethod QueryMethod
+		args        []interface{}
+		ok          bool
+	}{
+		{BoundingBox, []interface{}{Drive, false, ovp.JSON, -37.9, 144.9, -37.8, 145.0}, true},
+		{BoundingBox, []interface{}{Drive, false, ovp.JSON, -37.9, 144.9}, false},
+		{Radius, []interface{}{Drive, false, ovp.JSON, "100", -37.8, 144.9}, false},
+		{BoundingBox, []interface{}{Drive, false, ovp.CSV, -37.9, 144.9, -37.8, 145.0}, false},
+		{QueryMethod("Unknown"), []interface{}{Drive, false, ovp.JSON}, false},
+	}
+
+	for _, test := range testCases {
+		query, err := GetPresetQueryE(test.queryMethod, test.args...)
+
+		if (err == nil) != test.ok || (query != nil) != test.ok {
+			t.Errorf("%v %v: expected ok %v, got %v (%v)", test.queryMethod, test.args, test.ok, query, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected GetPresetQuery to panic for invalid args")
+		}
+	}()
+
+	GetPresetQuery(BoundingBox, Drive, false, ovp.JSON, -37.9, 144.9)
+}
+
+func TestPresetQueryByMultiPolygon(t *testing.T) {
+	square := func(lon float64, lat float64, size float64) [][]float64 {
+		return [][]float64{{lon, lat}, {lon + size, lat}, {lon + size, lat + size}, {lon, lat + size}, {lon, lat}}
+	}
+
+	query, err := GetPresetQueryE(
+		MultiPolygon,
+		Drive,
+		false,
+		ovp.JSON,
+		[][][][]float64{
+			{square(145.0, -37.9, 0.1), square(145.04, -37.86, 0.02)},
+			{square(145.2, -37.9, 0.05)},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	compiled := query.GetCompiled()
+
+	for _, expected := range []string{
+		`(way["highway"]`,
+		`(poly:"-37.86 145.04 -37.86 145.06 `,
+		`);)->.way_holes_0;`,
+		` - .way_holes_0;)->.way_0;`,
+		` - .relation_holes_0;)->.relation_0;`,
+		`(.way_0;>;.relation_0;>;way[`,
+		`(poly:"-37.9 145.2 -37.9 145.25 -37.85 145.25 -37.85 145.2 -37.9 145.2");`,
+	} {
+		if !strings.Contains(compiled, expected) {
+			t.Errorf("expected %s in query %s", expected, compiled)
+		}
+	}
+
+	// The first ring of a polygon is the exterior, the rest are holes
+	polygon, err := GetPresetQueryE(Polygon, Drive, false, ovp.JSON, [][][]float64{square(145.0, -37.9, 0.1), square(145.04, -37.86, 0.02)})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(polygon.GetCompiled(), "->.way_holes_0;") {
+		t.Errorf("expected holes to be excluded in query %s", polygon.GetCompiled())
+	}
+}
+
+func TestPresetQueryByArea(t *testing.T) {
+	areaID, err := ovp.AreaID(ovp.Relation, 2316741)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, err := GetPresetQueryE(Area, Drive, true, ovp.JSON, areaID)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	compiled := query.GetCompiled()
+
+	for _, expected := range []string{
+		`[out:json];`,
+		`(area:3602316741);>;relation[`,
+		`(area:3602316741);>;)->._;out body;out meta;`,
+	} {
+		if !strings.Contains(compiled, expected) {
+			t.Errorf("expected %s in query %s", expected, compiled)
+		}
+	}
+}
+
+func TestPresetQueryByPlaceNameArea(t *testing.T) {
+	nominatimServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[{"osm_type": "relation", "osm_id": 2316741, "lat": "-37.0", "lon": "144.0", "geojson": {"type": "Polygon", "coordinates": [[[144, -37], [145, -37], [145, -38], [144, -37]]]}}]`)
+	}))
+	defer nominatimServer.Close()
+
+	nominatimClient := nom.NewClient(nominatimServer.URL+"/", "osmdata-test")
+	nominatimClient.Limiter = nil
+	nominatimClient.RetryPolicy = nil
+
+	var testCases = []struct {
+		response string
+		expected string
+	}{
+		{`{"elements":[{"type":"area","id":3602316741}]}`, `(area:3602316741);`},
+		{`{"elements":[]}`, `(poly:"-37 144 -37 145 -38 145 -37 144");`},
+	}
+
+	for _, test := range testCases {
+		overpassServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, test.response)
+		}))
+		defer overpassServer.Close()
+
+		overpassClient := ovp.NewClient(overpassServer.URL, overpassServer.URL)
+		query, err := GetPresetQueryByPlaceNameArea(context.Background(), overpassClient, nominatimClient, Drive, false, ovp.JSON, "Victoria, Australia")
+
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.response, err)
+		}
+
+		if compiled := query.GetCompiled(); !strings.Contains(compiled, test.expected) {
+			t.Errorf("%v: expected %s in query %s", test.response, test.expected, compiled)
+		}
+	}
+
+	// Errors of the lookups are returned rather than falling back
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	overpassClient := ovp.NewClient(failing.URL, failing.URL)
+	overpassClient.RetryPolicy = nil
+
+	if _, err := GetPresetQueryByPlaceNameArea(context.Background(), overpassClient, nominatimClient, Drive, false, ovp.JSON, "Victoria, Australia"); err == nil {
+		t.Errorf("expected error for a failing Overpass area lookup")
+	}
+}
+
+func TestPresetQuerySimplified(t *testing.T) {
+	// A circle of 2000 vertices, about 5 km across
+	ring := make([][]float64, 2001)
+	for i := range ring {
+		a := 2 * math.Pi * float64(i%2000) / 2000
+		ring[i] = []float64{144.95 + 0.03*math.Cos(a), -37.8 + 0.024*math.Sin(a)}
+	}
+
+	query, err := GetPresetQueryE(Polygon, Drive, false, ovp.JSON, [][][]float64{ring}, &helpers.SimplifyOptions{Tolerance: 10, MaxVertices: 100})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	compiled := query.GetCompiled()
+	poly := compiled[strings.Index(compiled, `poly:"`)+6:]
+	poly = poly[:strings.Index(poly, `"`)]
+
+	if vertices := len(strings.Fields(poly)) / 2; vertices == 0 || vertices > 100 {
+		t.Errorf("expected at most 100 vertices, got %d in query %s", vertices, compiled)
+	}
+
+	if _, err := GetPresetQueryE(Polygon, Drive, false, ovp.JSON, [][][]float64{ring}, &helpers.SimplifyOptions{MaxVertices: 3}); err == nil {
+		t.Errorf("expected error for an unreachable vertex budget")
+	}
+
+	if _, err := GetPresetQueryE(MultiPolygon, Drive, false, ovp.CSV, [][][][]float64{{ring}}, &helpers.SimplifyOptions{MaxVertices: 100}, "(::id)"); err != nil {
+		t.Errorf("unexpected error with output format options after the simplify options: %v", err)
+	}
+
+	if _, err := GetPresetQueryE(Area, Drive, false, ovp.JSON, int64(3602316741), &helpers.SimplifyOptions{}); err == nil {
+		t.Errorf("expected error for simplify options on an area query")
+	}
+
+	// The query without simplification is unchanged
+	if query, err = GetPresetQueryE(Polygon, Drive, false, ovp.JSON, [][][]float64{ring}); err != nil || strings.Count(query.GetCompiled(), " ") < 2000 {
+		t.Errorf("expected all vertices without simplification (%v)", err)
+	}
+}